@@ -0,0 +1,480 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*Aggregate)(nil)
+
+// Aggregate closes the current aggregation round of an entity collection,
+// the result is written to state so that every validator attests to the same value.
+// Publishers whose submissions are accepted share the round reward from the
+// reward pool.
+type Aggregate struct {
+	EntityIndex uint64 `json:"entity_index"`
+
+	// Round must be the current round of the collection, its result is
+	// recorded under it to serve point-in-time queries
+	Round uint64 `json:"round"`
+
+	// Publishers must contain every publisher of the round, their stake is
+	// settled and slashed on aggregation
+	Publishers []crypto.PublicKey `json:"publishers"`
+}
+
+func (*Aggregate) GetTypeID() uint8 {
+	return aggregateID
+}
+
+func (a *Aggregate) StateKeys(_ chain.Auth, _ ids.ID) [][]byte {
+	keys := [][]byte{
+		storage.PrefixEntityRoundKey(a.EntityIndex),
+		storage.PrefixAggregationCacheResult(a.EntityIndex),
+		storage.PrefixEntityMetaKey(a.EntityIndex),
+		storage.PrefixObservationsKey(a.EntityIndex),
+		storage.PrefixAggregationHistoryKey(a.EntityIndex, a.Round),
+		storage.PrefixSubscriptionsKey(a.EntityIndex),
+	}
+	if len(a.Publishers) > 0 {
+		keys = append(keys, storage.RewardPoolKey())
+	}
+	for _, publisher := range a.Publishers {
+		keys = append(keys,
+			storage.PrefixStakeKey(publisher),
+			storage.PrefixBalanceKey(publisher),
+			storage.PrefixRewardKey(publisher),
+		)
+	}
+
+	return keys
+}
+
+func (a *Aggregate) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	_ chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	unitsUsed := a.MaxUnits(r)
+
+	exists, meta, err := storage.GetEntityMeta(ctx, db, a.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputEntityNotRegistered}, nil
+	}
+
+	round, err := storage.GetEntityRound(ctx, db, a.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if round.Round != a.Round {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputRoundMismatch}, nil
+	}
+
+	if len(round.Submissions) == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputRoundEmpty}, nil
+	}
+
+	// only aggregate entities submitted in previous blocks
+	if round.StartTick >= t {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputRoundNotClosed}, nil
+	}
+
+	// stake keys of every publisher must be declared ahead of execution
+	submissions := make(map[crypto.PublicKey]uint64, len(a.Publishers))
+	for _, publisher := range a.Publishers {
+		submissions[publisher] = 0
+	}
+	for _, s := range round.Submissions {
+		count, ok := submissions[s.Publisher]
+		if !ok {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputMissingPublisher}, nil
+		}
+		submissions[s.Publisher] = count + 1
+	}
+
+	// weighted aggregators weight submissions by the stake of their publisher
+	// before slashing, publishers without stake keep the minimal weight
+	weights := make([]uint64, 0, len(round.Submissions))
+	entities := make([]oracle.Entity, 0, len(round.Submissions))
+	for _, s := range round.Submissions {
+		entity, err := oracle.RestoreEntity(round.EntityType, s.Publisher, s.Tick, s.Payload)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		entities = append(entities, entity)
+
+		stake, _, err := storage.GetStake(ctx, db, s.Publisher)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		if stake == 0 {
+			stake = 1
+		}
+		weights = append(weights, stake)
+	}
+
+	filter, err := oracle.ParseOutlierFilter(meta.Params)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	// rounds without a result are closed as stale below, otherwise stake of
+	// their publishers would stay locked and the round would fill up
+	result, outliers, err := oracle.Aggregate(round.EntityType, meta.Aggregator, meta.Params, filter, t, entities, weights)
+	noResult := errors.Is(err, oracle.ErrNoAcceptedEntities) || errors.Is(err, oracle.ErrNoConsensus)
+	if err != nil && !noResult {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	// publishers with a submission rejected as outlier are not rewarded
+	rejected := make([]*oracle.RejectedEntity, 0, len(outliers))
+	discarded := make(map[crypto.PublicKey]bool, len(outliers))
+	excluded := make(map[int]bool, len(outliers))
+	for _, i := range outliers {
+		publisher := round.Submissions[i].Publisher
+		rejected = append(rejected, oracle.NewRejectedEntity(publisher, entities[i]))
+		discarded[publisher] = true
+		excluded[i] = true
+	}
+
+	// the result is only published when accepted entities come from enough
+	// distinct publishers, otherwise the previous result is retained as stale
+	quorum, err := oracle.ParseQuorum(meta.Params)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	contributing := make([]oracle.Entity, 0, len(entities))
+	for i, e := range entities {
+		if !excluded[i] {
+			contributing = append(contributing, e)
+		}
+	}
+	if noResult || !quorum.Reached(contributing) {
+		output, wm, err := a.closeStale(ctx, r, db, t, round, submissions)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		if len(rejected) > 0 {
+			output.Rejected = rejected
+		}
+		payload, err := output.Marshal()
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		return &chain.Result{Success: true, Units: unitsUsed, Output: payload, WarpMessage: wm}, nil
+	}
+
+	// publishers deviating beyond the band from the result are slashed once per round
+	deviated := make(map[crypto.PublicKey]bool, len(submissions))
+	if band := fetchUint64(r, consts.SlashingBandKey); band > 0 {
+		for i, e := range entities {
+			deviation, err := oracle.Deviation(round.EntityType, e, result)
+			if err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+			}
+			if deviation > band {
+				deviated[round.Submissions[i].Publisher] = true
+			}
+		}
+	}
+
+	pool, err := storage.GetRewardPool(ctx, db)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	ratio := fetchUint64(r, consts.SlashingRatioKey)
+	accepted := make([]crypto.PublicKey, 0, len(submissions))
+	for publisher, count := range submissions {
+		if count == 0 {
+			continue
+		}
+		amount, pending, err := storage.GetStake(ctx, db, publisher)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		if pending < count {
+			pending = count
+		}
+		if deviated[publisher] {
+			// slashed stake is redistributed through the reward pool
+			slash := slashed(amount, ratio)
+			amount -= slash
+			pool, err = smath.Add64(pool, slash)
+			if err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+			}
+		} else if !discarded[publisher] {
+			accepted = append(accepted, publisher)
+		}
+		if err := storage.SetStake(ctx, db, publisher, amount, pending-count); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+	}
+
+	// round reward is split evenly between accepted publishers, the remainder
+	// stays in the pool
+	reward := fetchUint64(r, consts.RoundRewardKey)
+	if reward > pool {
+		reward = pool
+	}
+	if len(accepted) > 0 {
+		share := reward / uint64(len(accepted))
+		for _, publisher := range accepted {
+			if share == 0 {
+				break
+			}
+			if err := storage.AddBalance(ctx, db, publisher, share); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+			}
+			if err := storage.AddReward(ctx, db, publisher, share); err != nil {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+			}
+			pool -= share
+		}
+	}
+	if len(submissions) > 0 {
+		if err := storage.SetRewardPool(ctx, db, pool); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+	}
+
+	payload, err := result.Marshal()
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	cache := &storage.AggregationCache{
+		EntityType:   round.EntityType,
+		Tick:         t,
+		Round:        round.Round,
+		Contributors: uint64(oracle.CountPublishers(contributing)),
+		Rejected:     uint64(len(rejected)),
+		Payload:      payload,
+	}
+	if err := storage.CacheAggregationResult(ctx, db, a.EntityIndex, cache); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	// results are kept per round to serve point-in-time queries
+	if err := storage.StoreAggregationHistory(ctx, db, a.EntityIndex, round.Round, t, cache); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	// results are observed to serve time-weighted averages
+	value, observed := oracle.Observe(round.EntityType, result)
+	if observed {
+		if err := storage.AddObservation(ctx, db, a.EntityIndex, t, value); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+	}
+
+	wm, err := a.push(ctx, r, db, t, cache, value, observed)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	if err := a.nextRound(ctx, db, round); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	output := oracle.NewEntityWithMeta(round.EntityType, a.EntityIndex, result)
+	if len(rejected) > 0 {
+		output.Rejected = rejected
+	}
+	payload, err = output.Marshal()
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed, Output: payload, WarpMessage: wm}, nil
+}
+
+// push returns the warp message pushing the result [cache] at [t] to
+// subscribed chains whose thresholds are crossed, nil if there are none.
+// Stale results are pushed without [observed] value, hence on heartbeat only.
+func (a *Aggregate) push(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	cache *storage.AggregationCache,
+	value uint64,
+	observed bool,
+) (*warp.UnsignedMessage, error) {
+	subs, err := storage.GetSubscriptions(ctx, db, a.EntityIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations := []ids.ID{}
+	for _, sub := range subs {
+		if !sub.Crossed(sub.LastValue, sub.LastTick, value, observed, t) {
+			continue
+		}
+		if observed {
+			sub.LastValue = value
+		}
+		sub.LastTick = t
+		destinations = append(destinations, sub.DestinationChainID)
+	}
+	if len(destinations) == 0 {
+		return nil, nil
+	}
+	if err := storage.SetSubscriptions(ctx, db, a.EntityIndex, subs); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(&PushResult{
+		DestinationChainIDs: destinations,
+		Result: &QueryResult{
+			EntityIndex:  a.EntityIndex,
+			EntityType:   cache.EntityType,
+			Payload:      cache.Payload,
+			Mode:         LatestQueryMode,
+			Tick:         cache.Tick,
+			Round:        cache.Round,
+			Contributors: cache.Contributors,
+			Rejected:     cache.Rejected,
+			Stale:        cache.Stale,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newQueryResponse(r, payload), nil
+}
+
+// closeStale closes a round without quorum or result, stake of its publishers is
+// released without slashing nor rewards and the cached result is marked stale.
+// The stale result is recorded as the result of the round closed at [t] and
+// pushed to subscribed chains whose heartbeat passed.
+func (a *Aggregate) closeStale(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	round *storage.EntityRound,
+	submissions map[crypto.PublicKey]uint64,
+) (*oracle.EntityWithMeta, *warp.UnsignedMessage, error) {
+	for publisher, count := range submissions {
+		if count == 0 {
+			continue
+		}
+		amount, pending, err := storage.GetStake(ctx, db, publisher)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pending < count {
+			pending = count
+		}
+		if err := storage.SetStake(ctx, db, publisher, amount, pending-count); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var wm *warp.UnsignedMessage
+	output := oracle.NewEntityWithMeta(round.EntityType, a.EntityIndex, nil)
+	output.Stale = true
+
+	cache, err := storage.GetCachedAggregationResult(ctx, db, a.EntityIndex)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+	case err != nil:
+		return nil, nil, err
+	default:
+		cache.Stale = true
+		if err := storage.CacheAggregationResult(ctx, db, a.EntityIndex, cache); err != nil {
+			return nil, nil, err
+		}
+		output.Entity, err = oracle.RestoreEntity(cache.EntityType, crypto.EmptyPublicKey, cache.Tick, cache.Payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		wm, err = a.push(ctx, r, db, t, cache, 0, false)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// every closed round is recorded so that rounds can be resolved from
+	// their close tick
+	if err := storage.StoreAggregationHistory(ctx, db, a.EntityIndex, round.Round, t, cache); err != nil {
+		return nil, nil, err
+	}
+
+	return output, wm, a.nextRound(ctx, db, round)
+}
+
+// nextRound opens the round following [round]
+func (a *Aggregate) nextRound(ctx context.Context, db chain.Database, round *storage.EntityRound) error {
+	next := &storage.EntityRound{
+		Round:       round.Round + 1,
+		EntityType:  round.EntityType,
+		Submissions: make([]*storage.RoundSubmission, 0),
+	}
+	return storage.StoreEntityRound(ctx, db, a.EntityIndex, next)
+}
+
+// slashed returns [ratio] basis points of [amount] without overflowing
+func slashed(amount uint64, ratio uint64) uint64 {
+	return amount/consts.BasisPoints*ratio + amount%consts.BasisPoints*ratio/consts.BasisPoints
+}
+
+func (a *Aggregate) MaxUnits(chain.Rules) uint64 {
+	return uint64(a.Size())
+}
+
+func (a *Aggregate) Size() int {
+	return hconsts.Uint64Len*2 + hconsts.IntLen + len(a.Publishers)*crypto.PublicKeyLen
+}
+
+func (a *Aggregate) Marshal(p *codec.Packer) {
+	p.PackUint64(a.EntityIndex)
+	p.PackUint64(a.Round)
+	p.PackInt(len(a.Publishers))
+	for _, publisher := range a.Publishers {
+		p.PackPublicKey(publisher)
+	}
+}
+
+func UnmarshalAggregate(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var aggregate Aggregate
+	// can be 0
+	aggregate.EntityIndex = p.UnpackUint64(false)
+	// can be 0
+	aggregate.Round = p.UnpackUint64(false)
+	count := p.UnpackInt(false)
+	if count > consts.RoundMaxSubmissions {
+		return nil, ErrTooManyPublishers
+	}
+	aggregate.Publishers = make([]crypto.PublicKey, count)
+	for i := 0; i < count; i++ {
+		p.UnpackPublicKey(true, &aggregate.Publishers[i])
+	}
+	return &aggregate, p.Err()
+}
+
+func (*Aggregate) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+type BatchQueryResult struct {
+	// results in the order of [BatchWarpQuery.EntityIndices]
+	Results []*QueryResult `json:"results"`
+}
+
+// BatchQuery answers a [BatchWarpQuery] with the results of all queried
+// entities in one warp message, the query fails if any entity can't be
+// answered
+type BatchQuery struct {
+	// latest rounds closed at the queried tick of [AtTickQueryMode], one per
+	// queried entity in the same order, see [Query.Round]
+	Rounds []uint64 `json:"rounds"`
+
+	warpQuery   *BatchWarpQuery
+	warpMessage *warp.Message
+}
+
+func (*BatchQuery) GetTypeID() uint8 {
+	return batchQueryID
+}
+
+func (q *BatchQuery) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	keys := [][]byte{
+		storage.PrefixQueryBalanceKey(q.warpMessage.SourceChainID),
+		storage.RewardPoolKey(),
+	}
+	for i, wq := range q.warpQuery.Queries() {
+		keys = append(keys, queryStateKeys(wq, q.closedRound(i))...)
+	}
+
+	return keys
+}
+
+func (q *BatchQuery) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	warpVerified bool,
+) (*chain.Result, error) {
+	unitsUsed := q.MaxUnits(r)
+	if !warpVerified {
+		return &chain.Result{
+			Success: false,
+			Units:   unitsUsed,
+			Output:  OutputWarpVerificationFailed,
+		}, nil
+	}
+
+	// results are only served to chains allowed to query
+	sourceChainID := q.warpMessage.SourceChainID
+	if !queryChainAllowed(r, sourceChainID) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputQueryChainNotAllowed}, nil
+	}
+
+	var batchRes BatchQueryResult
+	for i, wq := range q.warpQuery.Queries() {
+		queryRes, output := answerQuery(ctx, db, t, wq, q.closedRound(i))
+		if queryRes == nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
+		}
+		queryRes.SourceChainID = sourceChainID
+		queryRes.DestinationChainID = wq.DestinationChainID
+		batchRes.Results = append(batchRes.Results, queryRes)
+	}
+
+	output, err := chargeQuery(ctx, db, sourceChainID, q.warpQuery.EntityIndices)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	if output != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
+	}
+
+	wmPayload, err := json.Marshal(batchRes)
+	if err != nil {
+		return &chain.Result{
+			Success: false,
+			Units:   unitsUsed,
+			Output:  OutputQueryResMarshalFailed,
+		}, nil
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed, WarpMessage: newQueryResponse(r, wmPayload)}, nil
+}
+
+// closedRound returns the round attached for the [i]th queried entity, only
+// set for [AtTickQueryMode]
+func (q *BatchQuery) closedRound(i int) uint64 {
+	if i < len(q.Rounds) {
+		return q.Rounds[i]
+	}
+	return 0
+}
+
+func (q *BatchQuery) MaxUnits(chain.Rules) uint64 {
+	return uint64(len(q.warpMessage.Payload))
+}
+
+func (q *BatchQuery) Size() int {
+	return hconsts.IntLen + hconsts.Uint64Len*len(q.Rounds)
+}
+
+func (q *BatchQuery) Marshal(p *codec.Packer) {
+	p.PackInt(len(q.Rounds))
+	for _, round := range q.Rounds {
+		p.PackUint64(round)
+	}
+}
+
+func UnmarshalBatchQuery(p *codec.Packer, wm *warp.Message) (chain.Action, error) {
+	var (
+		query BatchQuery
+		err   error
+	)
+
+	count := p.UnpackInt(false)
+	if count > consts.BatchQueryMaxEntities {
+		return nil, ErrInvalidBatchSize
+	}
+	for i := 0; i < count; i++ {
+		// can be 0
+		query.Rounds = append(query.Rounds, p.UnpackUint64(false))
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	query.warpMessage = wm
+	query.warpQuery, err = UnmarshalBatchWarpQuery(query.warpMessage.Payload)
+
+	if err != nil {
+		return nil, err
+	}
+	// time queries carry the round closed at the tick of every entity
+	expected := 0
+	if query.warpQuery.Mode == AtTickQueryMode {
+		expected = len(query.warpQuery.EntityIndices)
+	}
+	if len(query.Rounds) != expected {
+		return nil, ErrRoundsMismatch
+	}
+
+	return &query, nil
+}
+
+func (q *BatchQuery) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -5,7 +5,16 @@ package actions
 
 // Note: Registry will error during initialization if a duplicate ID is assigned. We explicitly assign IDs to avoid accidental remapping.
 const (
-	transferID     uint8 = 0
-	uploadEntityID uint8 = 1
-	queryID        uint8 = 2
+	transferID       uint8 = 0
+	uploadEntityID   uint8 = 1
+	queryID          uint8 = 2
+	aggregateID      uint8 = 3
+	registerEntityID uint8 = 4
+	updateFeederID   uint8 = 5
+	stakeID          uint8 = 6
+	unstakeID        uint8 = 7
+	batchQueryID     uint8 = 8
+	fundQueriesID    uint8 = 9
+	subscribeID      uint8 = 10
+	submitReportID   uint8 = 11
 )
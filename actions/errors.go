@@ -0,0 +1,21 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import "errors"
+
+var ErrTooManyPublishers = errors.New("too many publishers")
+var ErrUnknownQueryMode = errors.New("unknown query mode")
+var ErrInvalidWindow = errors.New("invalid time window")
+var ErrInvalidMaxAge = errors.New("invalid max age")
+var ErrInvalidTick = errors.New("invalid tick")
+var ErrInvalidBatchSize = errors.New("invalid number of entities in batch")
+var ErrDuplicateEntityIndex = errors.New("duplicate entity index")
+var ErrRoundsMismatch = errors.New("rounds do not match queried entities")
+var ErrInvalidHeartbeat = errors.New("invalid heartbeat")
+var ErrInvalidSigners = errors.New("invalid number of report signers")
+var ErrInvalidObservations = errors.New("invalid number of report observations")
+var ErrDuplicateSigner = errors.New("duplicate report signer")
+var ErrUnknownSigner = errors.New("unknown report signer")
+var ErrDuplicateObservation = errors.New("duplicate observation of signer")
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/auth"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*FundQueries)(nil)
+
+// FundQueries prepays [Amount] of the actor balance for query fees of
+// [ChainID], any account (e.g. a relayer) can fund any chain
+type FundQueries struct {
+	ChainID ids.ID `json:"chainID"`
+	Amount  uint64 `json:"amount"`
+}
+
+func (*FundQueries) GetTypeID() uint8 {
+	return fundQueriesID
+}
+
+func (f *FundQueries) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixBalanceKey(auth.GetActor(rauth)),
+		storage.PrefixQueryBalanceKey(f.ChainID),
+	}
+}
+
+func (f *FundQueries) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := f.MaxUnits(r)
+	if f.Amount == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+
+	balance, err := storage.GetQueryBalance(ctx, db, f.ChainID)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	balance, err = smath.Add64(balance, f.Amount)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	if err := storage.SubBalance(ctx, db, actor, f.Amount); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.SetQueryBalance(ctx, db, f.ChainID, balance); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*FundQueries) MaxUnits(chain.Rules) uint64 {
+	return hconsts.IDLen + hconsts.Uint64Len
+}
+
+func (*FundQueries) Size() int {
+	return hconsts.IDLen + hconsts.Uint64Len
+}
+
+func (f *FundQueries) Marshal(p *codec.Packer) {
+	p.PackID(f.ChainID)
+	p.PackUint64(f.Amount)
+}
+
+func UnmarshalFundQueries(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var fund FundQueries
+	p.UnpackID(true, &fund.ChainID)
+	fund.Amount = p.UnpackUint64(true)
+	return &fund, p.Err()
+}
+
+func (*FundQueries) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
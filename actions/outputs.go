@@ -8,3 +8,34 @@ var PayloadSizeTooLarge = []byte("payload size too large")
 var OutputWarpVerificationFailed = []byte("warp verificatinon failed")
 var OutputEntityNotRecorded = []byte("entity id out of range or entity not gets recorded")
 var OutputQueryResMarshalFailed = []byte("failed to unmarshal query result")
+var OutputEntityTypeMismatch = []byte("entity type mismatches the entity collection")
+var OutputEntityNotRegistered = []byte("entity collection is not registered")
+var OutputEntityIndexMismatch = []byte("entity index is not the next index to register")
+var OutputRoundFull = []byte("aggregation round is full")
+var OutputRoundEmpty = []byte("aggregation round is empty")
+var OutputRoundNotClosed = []byte("aggregation round started in current block")
+var OutputUnauthorizedPublisher = []byte("publisher is not authorized to upload to the entity collection")
+var OutputNotAdmin = []byte("actor is not the admin")
+var OutputTooManyFeeders = []byte("too many feeders authorized for the entity collection")
+var OutputMissingPublisher = []byte("publisher of the round is missing from the aggregation")
+var OutputStakeLocked = []byte("stake is locked by submissions waiting for aggregation")
+var OutputInsufficientStake = []byte("insufficient stake")
+var OutputNoObservations = []byte("no aggregation results observed within the time window")
+var OutputResultTooOld = []byte("aggregation result is older than the max age of the query")
+var OutputQueryPointInFuture = []byte("queried point is not in the past")
+var OutputInsufficientQueryBalance = []byte("insufficient query balance of the querying chain")
+var OutputQueryChainNotAllowed = []byte("querying chain is not allowed")
+var OutputSubscriptionExpired = []byte("subscription request tick is outside of the validity window")
+var OutputSubscriptionOutdated = []byte("subscription request is not newer than the last request of the chain")
+var OutputSubscriptionNotFound = []byte("subscription not found")
+var OutputTooManySubscriptions = []byte("too many subscriptions")
+var OutputPointNotRecorded = []byte("no aggregation result recorded at the queried point")
+var OutputRoundNotLatest = []byte("queried round is not the latest round closed at the queried tick")
+var OutputRoundMismatch = []byte("round is not the current aggregation round")
+var OutputMalformedPayload = []byte("malformed entity payload")
+var OutputEntityNotSupported = []byte("entity type is not supported")
+var OutputInvalidReportSignature = []byte("invalid signature of report signer")
+var OutputReportExpired = []byte("report tick is outside of the validity window")
+var OutputReportRoundMismatch = []byte("report observes another aggregation round")
+var OutputReportSubmitted = []byte("report is already submitted")
+var OutputTooManyReports = []byte("too many reports submitted to the round")
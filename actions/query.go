@@ -5,18 +5,45 @@ import (
 	"encoding/json"
 
 	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+	"github.com/bianyuanop/oraclevm/oracle"
 	"github.com/bianyuanop/oraclevm/storage"
 )
 
 type QueryResult struct {
-	EntityType uint64 `json:"entityType"`
-	Payload    []byte `json:"payload"`
+	// chain issuing the query and chain the result is delivered to
+	SourceChainID      ids.ID `json:"sourceChainID"`
+	DestinationChainID ids.ID `json:"destinationChainID"`
+
+	EntityIndex uint64 `json:"entityIndex"`
+	EntityType  uint64 `json:"entityType"`
+	Payload     []byte `json:"payload"`
+	// mode and window of the [WarpQuery] answered
+	Mode   uint64 `json:"mode"`
+	Window int64  `json:"window,omitempty"`
+	// the latest round closed without reaching quorum, the result is
+	// retained from an earlier round
+	Stale bool `json:"stale"`
+	// block timestamp, round and number of distinct publishers of the
+	// aggregation result, and number of submissions rejected as outliers
+	Tick         int64  `json:"tick"`
+	Round        uint64 `json:"round"`
+	Contributors uint64 `json:"contributors"`
+	Rejected     uint64 `json:"rejected"`
 }
 
 type Query struct {
+	// latest round closed at the queried tick of [AtTickQueryMode], resolved
+	// by the relayer with the `roundAt` RPC method and checked on execution
+	// so that the queried state is known ahead of execution
+	Round uint64 `json:"round"`
+
 	warpQuery   *WarpQuery
 	warpMessage *warp.Message
 }
@@ -26,11 +53,11 @@ func (*Query) GetTypeID() uint8 {
 }
 
 func (q *Query) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
-	keys := [][]byte{
-		storage.PrefixAggregationCacheResult(q.warpQuery.EntityIndex),
-	}
-
-	return keys
+	return append(
+		queryStateKeys(q.warpQuery, q.Round),
+		storage.PrefixQueryBalanceKey(q.warpMessage.SourceChainID),
+		storage.RewardPoolKey(),
+	)
 }
 
 func (q *Query) Execute(
@@ -52,19 +79,26 @@ func (q *Query) Execute(
 		}, nil
 	}
 
-	var queryRes QueryResult
-	entityType, payload, err := storage.GetCachedAggregationResult(ctx, db, q.warpQuery.EntityIndex)
-	if err != nil {
-		return &chain.Result{
-			Success: false,
-			Units:   unitsUsed,
-			Output:  OutputEntityNotRecorded,
-		}, nil
+	// results are only served to chains allowed to query
+	sourceChainID := q.warpMessage.SourceChainID
+	if !queryChainAllowed(r, sourceChainID) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputQueryChainNotAllowed}, nil
 	}
 
-	queryRes.EntityType = entityType
-	// payload is `Entity.Marshal()`
-	queryRes.Payload = payload
+	queryRes, output := answerQuery(ctx, db, t, q.warpQuery, q.Round)
+	if queryRes == nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
+	}
+	queryRes.SourceChainID = sourceChainID
+	queryRes.DestinationChainID = q.warpQuery.DestinationChainID
+
+	output, err := chargeQuery(ctx, db, sourceChainID, []uint64{q.warpQuery.EntityIndex})
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	if output != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
+	}
 
 	wmPayload, err := json.Marshal(queryRes)
 	if err != nil {
@@ -75,11 +109,190 @@ func (q *Query) Execute(
 		}, nil
 	}
 
-	wm := &warp.UnsignedMessage{
-		Payload: wmPayload,
+	return &chain.Result{Success: true, Units: unitsUsed, WarpMessage: newQueryResponse(r, wmPayload)}, nil
+}
+
+// newQueryResponse wraps query results sent from this chain, warp messages
+// carry no destination so relayers deliver results to the destination chain
+// recorded in [payload]
+func newQueryResponse(r chain.Rules, payload []byte) *warp.UnsignedMessage {
+	return &warp.UnsignedMessage{
+		NetworkID:     r.NetworkID(),
+		SourceChainID: r.ChainID(),
+		Payload:       payload,
+	}
+}
+
+// queryStateKeys returns the state keys read to answer [wq], [round] is the
+// latest round closed at the queried tick of [AtTickQueryMode]
+func queryStateKeys(wq *WarpQuery, round uint64) [][]byte {
+	keys := [][]byte{
+		storage.PrefixAggregationCacheResult(wq.EntityIndex),
+		storage.PrefixEntityMetaKey(wq.EntityIndex),
+	}
+	switch wq.Mode {
+	case TWAPQueryMode:
+		keys = append(keys, storage.PrefixObservationsKey(wq.EntityIndex))
+	case AtTickQueryMode:
+		// the following round must not have closed by the queried tick
+		keys = append(keys,
+			storage.PrefixAggregationHistoryKey(wq.EntityIndex, round),
+			storage.PrefixAggregationHistoryKey(wq.EntityIndex, round+1),
+		)
+	case AtRoundQueryMode:
+		keys = append(keys,
+			storage.PrefixAggregationHistoryKey(wq.EntityIndex, wq.Round),
+			storage.PrefixEntityRoundKey(wq.EntityIndex),
+		)
+	}
+
+	return keys
+}
+
+// chargeQuery settles the query fees of [entityIndices] from the balance
+// prepaid for [chainID], fees fund the reward pool of publishers. The output
+// explains the failure if the fees can't be settled.
+func chargeQuery(ctx context.Context, db chain.Database, chainID ids.ID, entityIndices []uint64) ([]byte, error) {
+	fee := uint64(0)
+	for _, entityIndex := range entityIndices {
+		exists, meta, err := storage.GetEntityMeta(ctx, db, entityIndex)
+		if err != nil {
+			return utils.ErrBytes(err), nil
+		}
+		if !exists {
+			return OutputEntityNotRegistered, nil
+		}
+		pricing, err := oracle.ParseQueryPricing(meta.Params)
+		if err != nil {
+			return utils.ErrBytes(err), nil
+		}
+		fee, err = smath.Add64(fee, pricing.QueryFee)
+		if err != nil {
+			return utils.ErrBytes(err), nil
+		}
+	}
+	if fee == 0 {
+		return nil, nil
+	}
+
+	balance, err := storage.GetQueryBalance(ctx, db, chainID)
+	if err != nil {
+		return utils.ErrBytes(err), nil
+	}
+	if balance < fee {
+		return OutputInsufficientQueryBalance, nil
+	}
+	pool, err := storage.GetRewardPool(ctx, db)
+	if err != nil {
+		return utils.ErrBytes(err), nil
+	}
+	pool, err = smath.Add64(pool, fee)
+	if err != nil {
+		return utils.ErrBytes(err), nil
+	}
+
+	if err := storage.SetQueryBalance(ctx, db, chainID, balance-fee); err != nil {
+		return nil, err
+	}
+	if err := storage.SetRewardPool(ctx, db, pool); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// answerQuery answers [wq] at block timestamp [t] from the latest round closed
+// at the queried tick [closedRound], the output explains the failure if no
+// result is returned
+func answerQuery(ctx context.Context, db chain.Database, t int64, wq *WarpQuery, closedRound uint64) (*QueryResult, []byte) {
+	cache, err := storage.GetCachedAggregationResult(ctx, db, wq.EntityIndex)
+	if err != nil {
+		return nil, OutputEntityNotRecorded
+	}
+
+	// historical queries answer the result valid at the queried point, the
+	// age of the result is measured at that point
+	reference := t
+	switch wq.Mode {
+	case AtTickQueryMode:
+		if wq.Tick > t {
+			return nil, OutputQueryPointInFuture
+		}
+		exists, closed, recorded, err := storage.GetAggregationHistory(ctx, db, wq.EntityIndex, closedRound)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		if !exists || closed > wq.Tick || recorded == nil {
+			return nil, OutputPointNotRecorded
+		}
+		exists, closed, _, err = storage.GetAggregationHistory(ctx, db, wq.EntityIndex, closedRound+1)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		if exists && closed <= wq.Tick {
+			return nil, OutputRoundNotLatest
+		}
+		cache = recorded
+		reference = wq.Tick
+	case AtRoundQueryMode:
+		round, err := storage.GetEntityRound(ctx, db, wq.EntityIndex)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		if wq.Round >= round.Round {
+			return nil, OutputQueryPointInFuture
+		}
+		// rounds closed without quorum record the result of an earlier
+		// round marked stale
+		_, closed, recorded, err := storage.GetAggregationHistory(ctx, db, wq.EntityIndex, wq.Round)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		if recorded == nil {
+			return nil, OutputPointNotRecorded
+		}
+		cache = recorded
+		reference = closed
+	}
+
+	// destination chains can bound the age of results they consume
+	if wq.MaxAge > 0 && reference-cache.Tick > wq.MaxAge {
+		return nil, OutputResultTooOld
+	}
+
+	queryRes := &QueryResult{}
+	entityType, payload := cache.EntityType, cache.Payload
+	if wq.Mode == TWAPQueryMode {
+		latest, err := oracle.RestoreEntity(entityType, crypto.EmptyPublicKey, cache.Tick, payload)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		observations, err := storage.GetObservations(ctx, db, wq.EntityIndex)
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		twap, err := oracle.TimeWeighted(entityType, latest, observations, t, wq.Window)
+		if err != nil {
+			return nil, OutputNoObservations
+		}
+		payload, err = twap.Marshal()
+		if err != nil {
+			return nil, utils.ErrBytes(err)
+		}
+		queryRes.Window = wq.Window
 	}
 
-	return &chain.Result{Success: true, Units: unitsUsed, WarpMessage: wm}, nil
+	queryRes.EntityIndex = wq.EntityIndex
+	queryRes.EntityType = entityType
+	// payload is `Entity.Marshal()`
+	queryRes.Payload = payload
+	queryRes.Mode = wq.Mode
+	queryRes.Stale = cache.Stale
+	queryRes.Tick = cache.Tick
+	queryRes.Round = cache.Round
+	queryRes.Contributors = cache.Contributors
+	queryRes.Rejected = cache.Rejected
+
+	return queryRes, nil
 }
 
 func (q *Query) MaxUnits(chain.Rules) uint64 {
@@ -87,10 +300,12 @@ func (q *Query) MaxUnits(chain.Rules) uint64 {
 }
 
 func (*Query) Size() int {
-	return 0
+	return hconsts.Uint64Len
 }
 
-func (q *Query) Marshal(_ *codec.Packer) {}
+func (q *Query) Marshal(p *codec.Packer) {
+	p.PackUint64(q.Round)
+}
 
 func UnmarshalQuery(p *codec.Packer, wm *warp.Message) (chain.Action, error) {
 	var (
@@ -98,6 +313,12 @@ func UnmarshalQuery(p *codec.Packer, wm *warp.Message) (chain.Action, error) {
 		err   error
 	)
 
+	// can be 0
+	query.Round = p.UnpackUint64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
 	query.warpMessage = wm
 	query.warpQuery, err = UnmarshalWarpQuery(query.warpMessage.Payload)
 
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/auth"
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*RegisterEntity)(nil)
+
+// RegisterEntity creates an entity collection in state, making the
+// index -> entity mapping identical on every node. Only the admin set in
+// genesis is allowed to register collections.
+type RegisterEntity struct {
+	// must be the number of entity collections registered so far
+	EntityIndex uint64 `json:"entity_index"`
+
+	EntityName string `json:"entity_name"`
+	EntityType uint64 `json:"entity_type"`
+	Aggregator uint64 `json:"aggregator"`
+	Params     []byte `json:"params"`
+}
+
+func (*RegisterEntity) GetTypeID() uint8 {
+	return registerEntityID
+}
+
+func (re *RegisterEntity) StateKeys(_ chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.AdminKey(),
+		storage.EntityCounterKey(),
+		storage.PrefixEntityMetaKey(re.EntityIndex),
+	}
+}
+
+func (re *RegisterEntity) Meta() *oracle.EntityCollectionMeta {
+	return &oracle.EntityCollectionMeta{
+		EntityName: re.EntityName,
+		EntityID:   re.EntityIndex,
+		EntityType: re.EntityType,
+		Aggregator: re.Aggregator,
+		Params:     re.Params,
+	}
+}
+
+func (re *RegisterEntity) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := re.MaxUnits(r)
+
+	exists, admin, err := storage.GetAdmin(ctx, db)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists || admin != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputNotAdmin}, nil
+	}
+
+	counter, err := storage.GetEntityCounter(ctx, db)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if re.EntityIndex != counter {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputEntityIndexMismatch}, nil
+	}
+
+	meta := re.Meta()
+	if err := meta.Verify(); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	if err := storage.StoreEntityMeta(ctx, db, meta); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	if err := storage.SetEntityCounter(ctx, db, counter+1); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (re *RegisterEntity) MaxUnits(chain.Rules) uint64 {
+	return uint64(re.Size())
+}
+
+func (re *RegisterEntity) Size() int {
+	return hconsts.Uint64Len*3 + codec.StringLen(re.EntityName) + codec.BytesLen(re.Params)
+}
+
+func (re *RegisterEntity) Marshal(p *codec.Packer) {
+	p.PackUint64(re.EntityIndex)
+	p.PackString(re.EntityName)
+	p.PackUint64(re.EntityType)
+	p.PackUint64(re.Aggregator)
+	p.PackBytes(re.Params)
+}
+
+func UnmarshalRegisterEntity(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var register RegisterEntity
+
+	// index, type and aggregator can be 0
+	register.EntityIndex = p.UnpackUint64(false)
+	register.EntityName = p.UnpackString(true)
+	register.EntityType = p.UnpackUint64(false)
+	register.Aggregator = p.UnpackUint64(false)
+	p.UnpackBytes(consts.EntityParamsMaxLen, false, &register.Params)
+
+	return &register, p.Err()
+}
+
+func (*RegisterEntity) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package actions
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/hypersdk/chain"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// fetchUint64 reads a custom rule defined in genesis, missing rules are 0
+func fetchUint64(r chain.Rules, key string) uint64 {
+	v, ok := r.FetchCustom(key)
+	if !ok {
+		return 0
+	}
+	n, ok := v.(uint64)
+	if !ok {
+		return 0
+	}
+	return n
+}
+
+// queryChainAllowed returns if [chainID] is allowed to query by the rules
+// defined in genesis, no chain is allowed if the rule is missing
+func queryChainAllowed(r chain.Rules, chainID ids.ID) bool {
+	v, ok := r.FetchCustom(consts.QueryChainsKey)
+	if !ok {
+		return false
+	}
+	chains, ok := v.([]ids.ID)
+	if !ok {
+		return false
+	}
+	for _, allowed := range chains {
+		if allowed == chainID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	smath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/auth"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*Stake)(nil)
+
+// Stake locks [Amount] of the actor balance, stake is slashed when
+// submissions of the actor deviate from the aggregation result
+type Stake struct {
+	Amount uint64 `json:"amount"`
+}
+
+func (*Stake) GetTypeID() uint8 {
+	return stakeID
+}
+
+func (s *Stake) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	return [][]byte{
+		storage.PrefixBalanceKey(actor),
+		storage.PrefixStakeKey(actor),
+	}
+}
+
+func (s *Stake) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := s.MaxUnits(r)
+	if s.Amount == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+
+	amount, pending, err := storage.GetStake(ctx, db, actor)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	amount, err = smath.Add64(amount, s.Amount)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	if err := storage.SubBalance(ctx, db, actor, s.Amount); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if err := storage.SetStake(ctx, db, actor, amount, pending); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*Stake) MaxUnits(chain.Rules) uint64 {
+	return hconsts.Uint64Len
+}
+
+func (*Stake) Size() int {
+	return hconsts.Uint64Len
+}
+
+func (s *Stake) Marshal(p *codec.Packer) {
+	p.PackUint64(s.Amount)
+}
+
+func UnmarshalStake(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var stake Stake
+	stake.Amount = p.UnpackUint64(true)
+	return &stake, p.Err()
+}
+
+func (*Stake) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
+
+var _ chain.Action = (*Unstake)(nil)
+
+// Unstake returns [Amount] of the actor stake to its balance, it fails while
+// any submission of the actor is waiting for aggregation
+type Unstake struct {
+	Amount uint64 `json:"amount"`
+}
+
+func (*Unstake) GetTypeID() uint8 {
+	return unstakeID
+}
+
+func (u *Unstake) StateKeys(rauth chain.Auth, _ ids.ID) [][]byte {
+	actor := auth.GetActor(rauth)
+	return [][]byte{
+		storage.PrefixBalanceKey(actor),
+		storage.PrefixStakeKey(actor),
+	}
+}
+
+func (u *Unstake) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := u.MaxUnits(r)
+	if u.Amount == 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputValueZero}, nil
+	}
+
+	amount, pending, err := storage.GetStake(ctx, db, actor)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if pending > 0 {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputStakeLocked}, nil
+	}
+	if amount < u.Amount {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInsufficientStake}, nil
+	}
+
+	if err := storage.SetStake(ctx, db, actor, amount-u.Amount, pending); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	if err := storage.AddBalance(ctx, db, actor, u.Amount); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (*Unstake) MaxUnits(chain.Rules) uint64 {
+	return hconsts.Uint64Len
+}
+
+func (*Unstake) Size() int {
+	return hconsts.Uint64Len
+}
+
+func (u *Unstake) Marshal(p *codec.Packer) {
+	p.PackUint64(u.Amount)
+}
+
+func UnmarshalUnstake(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var unstake Unstake
+	unstake.Amount = p.UnpackUint64(true)
+	return &unstake, p.Err()
+}
+
+func (*Unstake) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,277 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*SubmitReport)(nil)
+
+// ReportSignature is the signature of [Signer] over the digest of a report
+type ReportSignature struct {
+	Signer    crypto.PublicKey `json:"signer"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+// ReportObservation is an entity observed by the signer at index [Signer] of
+// the report for the aggregation round [Round] of [EntityIndex]
+type ReportObservation struct {
+	Signer      uint8  `json:"signer"`
+	EntityType  uint64 `json:"entityType"`
+	EntityIndex uint64 `json:"entityIndex"`
+	Round       uint64 `json:"round"`
+	Payload     []byte `json:"payload"`
+}
+
+// SubmitReport transmits observations of many feeders to many entity
+// collections in one transaction. Every signer signs the [Digest] of the
+// report off chain, the transmitter only pays fees and needs no
+// authorization. Observations are submitted as if uploaded by their signers,
+// the report fails if any signature or observation is invalid. Reports are
+// valid within the validity window of transactions around [Tick], each report
+// is only submitted once to the rounds it observes and can't be replayed into
+// later rounds.
+type SubmitReport struct {
+	Tick         int64                `json:"tick"`
+	Signatures   []*ReportSignature   `json:"signatures"`
+	Observations []*ReportObservation `json:"observations"`
+}
+
+func (*SubmitReport) GetTypeID() uint8 {
+	return submitReportID
+}
+
+func (sr *SubmitReport) StateKeys(_ chain.Auth, txID ids.ID) [][]byte {
+	keys := [][]byte{}
+	for i, obs := range sr.Observations {
+		signer := sr.Signatures[obs.Signer].Signer
+		keys = append(keys, submissionStateKeys(ObservationID(txID, i), obs.EntityIndex, signer)...)
+	}
+
+	return keys
+}
+
+func (sr *SubmitReport) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	_ chain.Auth,
+	txID ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	unitsUsed := sr.MaxUnits(r)
+
+	if window := r.GetValidityWindow(); sr.Tick < t-window || sr.Tick > t+window {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputReportExpired}, nil
+	}
+
+	// signatures are bound to the chain so that reports can't be replayed
+	// on other chains
+	digest := sr.Digest(r.ChainID())
+	for _, sig := range sr.Signatures {
+		if !crypto.Verify(digest, sig.Signer, sig.Signature) {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputInvalidReportSignature}, nil
+		}
+	}
+
+	// a replayed report would overwrite later uploads of its signers, rounds
+	// keep the reports submitted to them until they are aggregated
+	reportID := sr.ID()
+	for i, obs := range sr.Observations {
+		round, err := storage.GetEntityRound(ctx, db, obs.EntityIndex)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+		}
+		if round.Round != obs.Round {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: observationOutput(i, OutputReportRoundMismatch)}, nil
+		}
+		for _, submitted := range round.Reports {
+			if submitted == reportID {
+				return &chain.Result{Success: false, Units: unitsUsed, Output: OutputReportSubmitted}, nil
+			}
+		}
+		if len(round.Reports) >= consts.RoundMaxReports {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: observationOutput(i, OutputTooManyReports)}, nil
+		}
+	}
+
+	for i, obs := range sr.Observations {
+		signer := sr.Signatures[obs.Signer].Signer
+		_, output, err := submitEntity(ctx, r, db, t, ObservationID(txID, i), signer, obs.EntityType, obs.EntityIndex, obs.Payload)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		if output != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: observationOutput(i, output)}, nil
+		}
+	}
+
+	recorded := make(map[uint64]struct{}, len(sr.Observations))
+	for _, obs := range sr.Observations {
+		if _, ok := recorded[obs.EntityIndex]; ok {
+			continue
+		}
+		recorded[obs.EntityIndex] = struct{}{}
+
+		round, err := storage.GetEntityRound(ctx, db, obs.EntityIndex)
+		if err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+		round.Reports = append(round.Reports, reportID)
+		if err := storage.StoreEntityRound(ctx, db, obs.EntityIndex, round); err != nil {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+		}
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+// observationOutput prefixes [output] with the index of the observation
+// rejecting the report
+func observationOutput(i int, output []byte) []byte {
+	return append([]byte(fmt.Sprintf("observation %d: ", i)), output...)
+}
+
+// ObservationID identifies the [i]th observation of the report submitted in
+// [txID], entities are stored under it
+func ObservationID(txID ids.ID, i int) ids.ID {
+	return txID.Prefix(uint64(i))
+}
+
+// ID identifies the tick, signers and observations of the report, submitted
+// reports are recorded in the rounds they observe under their ID
+func (sr *SubmitReport) ID() ids.ID {
+	return utils.ToID(sr.Digest(ids.Empty)[hconsts.IDLen:])
+}
+
+// Digest returns the message signed by every signer of the report on
+// [chainID], it covers the tick, signers and observations of the report
+func (sr *SubmitReport) Digest(chainID ids.ID) []byte {
+	p := codec.NewWriter(hconsts.IDLen+sr.Size(), hconsts.MaxInt)
+	p.PackID(chainID)
+	p.PackInt64(sr.Tick)
+	p.PackInt(len(sr.Signatures))
+	for _, sig := range sr.Signatures {
+		p.PackPublicKey(sig.Signer)
+	}
+	sr.packObservations(p)
+
+	return p.Bytes()
+}
+
+// Sign sets the signature of [priv] over the digest of the report, the
+// signer must be listed in [Signatures]
+func (sr *SubmitReport) Sign(chainID ids.ID, priv crypto.PrivateKey) error {
+	pk := priv.PublicKey()
+	for _, sig := range sr.Signatures {
+		if sig.Signer == pk {
+			sig.Signature = crypto.Sign(sr.Digest(chainID), priv)
+			return nil
+		}
+	}
+	return ErrUnknownSigner
+}
+
+func (sr *SubmitReport) MaxUnits(chain.Rules) uint64 {
+	// signatures are as expensive as the signature of transactions
+	return uint64(sr.Size()) + uint64(len(sr.Signatures))*crypto.SignatureLen*5
+}
+
+func (sr *SubmitReport) Size() int {
+	size := hconsts.Uint64Len + hconsts.IntLen*2 + len(sr.Signatures)*(crypto.PublicKeyLen+crypto.SignatureLen)
+	for _, obs := range sr.Observations {
+		size += 1 + hconsts.Uint64Len*3 + hconsts.IntLen + len(obs.Payload)
+	}
+
+	return size
+}
+
+func (sr *SubmitReport) Marshal(p *codec.Packer) {
+	p.PackInt64(sr.Tick)
+	p.PackInt(len(sr.Signatures))
+	for _, sig := range sr.Signatures {
+		p.PackPublicKey(sig.Signer)
+		p.PackSignature(sig.Signature)
+	}
+	sr.packObservations(p)
+}
+
+func (sr *SubmitReport) packObservations(p *codec.Packer) {
+	p.PackInt(len(sr.Observations))
+	for _, obs := range sr.Observations {
+		p.PackByte(obs.Signer)
+		p.PackUint64(obs.EntityType)
+		p.PackUint64(obs.EntityIndex)
+		p.PackUint64(obs.Round)
+		p.PackBytes(obs.Payload)
+	}
+}
+
+func UnmarshalSubmitReport(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var report SubmitReport
+	report.Tick = p.UnpackInt64(true)
+
+	count := p.UnpackInt(true)
+	if count > consts.ReportMaxSigners {
+		return nil, ErrInvalidSigners
+	}
+	signers := make(map[crypto.PublicKey]struct{}, count)
+	report.Signatures = make([]*ReportSignature, count)
+	for i := 0; i < count; i++ {
+		sig := new(ReportSignature)
+		p.UnpackPublicKey(true, &sig.Signer)
+		p.UnpackSignature(&sig.Signature)
+		if _, ok := signers[sig.Signer]; ok {
+			return nil, ErrDuplicateSigner
+		}
+		signers[sig.Signer] = struct{}{}
+		report.Signatures[i] = sig
+	}
+
+	count = p.UnpackInt(true)
+	if count > consts.ReportMaxObservations {
+		return nil, ErrInvalidObservations
+	}
+	// a signer observes each collection at most once per report
+	observed := make(map[[2]uint64]struct{}, count)
+	report.Observations = make([]*ReportObservation, count)
+	for i := 0; i < count; i++ {
+		obs := new(ReportObservation)
+		obs.Signer = p.UnpackByte()
+		// all can be 0
+		obs.EntityType = p.UnpackUint64(false)
+		obs.EntityIndex = p.UnpackUint64(false)
+		obs.Round = p.UnpackUint64(false)
+		p.UnpackBytes(consts.PayloadMaxLen, true, &obs.Payload)
+		if p.Err() != nil {
+			return nil, p.Err()
+		}
+		if int(obs.Signer) >= len(report.Signatures) {
+			return nil, ErrUnknownSigner
+		}
+		k := [2]uint64{uint64(obs.Signer), obs.EntityIndex}
+		if _, ok := observed[k]; ok {
+			return nil, ErrDuplicateObservation
+		}
+		observed[k] = struct{}{}
+		report.Observations[i] = obs
+	}
+
+	return &report, p.Err()
+}
+
+func (*SubmitReport) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
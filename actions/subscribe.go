@@ -0,0 +1,152 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*Subscribe)(nil)
+
+// Subscribe registers the chain sending [WarpSubscription] by warp message
+// for pushes of aggregation results, results are pushed by `Aggregate` when
+// they deviate from the last pushed value by [WarpSubscription.Deviation]
+// basis points or [WarpSubscription.Heartbeat] milliseconds passed since the
+// last push. Only the subscribed chain updates its thresholds by subscribing
+// again, and cancels it with both thresholds set to 0.
+type Subscribe struct {
+	warpSubscription *WarpSubscription
+	warpMessage      *warp.Message
+}
+
+// PushResult is emitted by `Aggregate` when results cross the thresholds of
+// subscribed chains, relayers deliver it to every destination chain
+type PushResult struct {
+	DestinationChainIDs []ids.ID     `json:"destinationChainIDs"`
+	Result              *QueryResult `json:"result"`
+}
+
+func (*Subscribe) GetTypeID() uint8 {
+	return subscribeID
+}
+
+func (s *Subscribe) StateKeys(_ chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.PrefixEntityMetaKey(s.warpSubscription.EntityIndex),
+		storage.PrefixSubscriptionsKey(s.warpSubscription.EntityIndex),
+	}
+}
+
+func (s *Subscribe) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	_ chain.Auth,
+	_ ids.ID,
+	warpVerified bool,
+) (*chain.Result, error) {
+	unitsUsed := s.MaxUnits(r)
+	// subscriptions are requested by the destination chain itself so that
+	// other accounts can't take its place
+	if !warpVerified {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputWarpVerificationFailed}, nil
+	}
+	ws, destinationChainID := s.warpSubscription, s.warpMessage.SourceChainID
+	if window := r.GetValidityWindow(); ws.Tick < t-window || ws.Tick > t+window {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSubscriptionExpired}, nil
+	}
+
+	exists, _, err := storage.GetEntityMeta(ctx, db, ws.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputEntityNotRegistered}, nil
+	}
+	// results are only pushed to chains allowed to query
+	if !queryChainAllowed(r, destinationChainID) {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputQueryChainNotAllowed}, nil
+	}
+
+	subs, err := storage.GetSubscriptions(ctx, db, ws.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	position := -1
+	for i, sub := range subs {
+		if sub.DestinationChainID == destinationChainID {
+			position = i
+			break
+		}
+	}
+	if position >= 0 && subs[position].Tick >= ws.Tick {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSubscriptionOutdated}, nil
+	}
+
+	threshold := oracle.PushThreshold{Deviation: ws.Deviation, Heartbeat: ws.Heartbeat}
+	switch {
+	case ws.Deviation == 0 && ws.Heartbeat == 0:
+		if position < 0 {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputSubscriptionNotFound}, nil
+		}
+		subs = append(subs[:position], subs[position+1:]...)
+	case position >= 0:
+		// the last push is kept so that updates don't trigger a push
+		subs[position].PushThreshold = threshold
+		subs[position].Tick = ws.Tick
+	default:
+		if len(subs) >= consts.EntityMaxSubscriptions {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputTooManySubscriptions}, nil
+		}
+		subs = append(subs, &storage.Subscription{
+			DestinationChainID: destinationChainID,
+			PushThreshold:      threshold,
+			Tick:               ws.Tick,
+		})
+	}
+
+	if err := storage.SetSubscriptions(ctx, db, ws.EntityIndex, subs); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (s *Subscribe) MaxUnits(chain.Rules) uint64 {
+	return uint64(len(s.warpMessage.Payload))
+}
+
+func (*Subscribe) Size() int {
+	return 0
+}
+
+// Marshal packs nothing, the request is carried by the warp message
+func (*Subscribe) Marshal(*codec.Packer) {}
+
+func UnmarshalSubscribe(_ *codec.Packer, wm *warp.Message) (chain.Action, error) {
+	var (
+		subscribe Subscribe
+		err       error
+	)
+
+	subscribe.warpMessage = wm
+	subscribe.warpSubscription, err = UnmarshalWarpSubscription(subscribe.warpMessage.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscribe, nil
+}
+
+func (*Subscribe) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/ava-labs/hypersdk/utils"
+
+	"github.com/bianyuanop/oraclevm/auth"
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+var _ chain.Action = (*UpdateFeeder)(nil)
+
+// UpdateFeeder authorizes or revokes a publisher of an entity collection,
+// only the admin set in genesis is allowed to update feeders
+type UpdateFeeder struct {
+	EntityIndex uint64           `json:"entity_index"`
+	Feeder      crypto.PublicKey `json:"feeder"`
+
+	// true to authorize [Feeder], false to revoke
+	Authorized bool `json:"authorized"`
+}
+
+func (*UpdateFeeder) GetTypeID() uint8 {
+	return updateFeederID
+}
+
+func (uf *UpdateFeeder) StateKeys(_ chain.Auth, _ ids.ID) [][]byte {
+	return [][]byte{
+		storage.AdminKey(),
+		storage.PrefixEntityMetaKey(uf.EntityIndex),
+		storage.PrefixEntityFeedersKey(uf.EntityIndex),
+	}
+}
+
+func (uf *UpdateFeeder) Execute(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	_ int64,
+	rauth chain.Auth,
+	_ ids.ID,
+	_ bool,
+) (*chain.Result, error) {
+	actor := auth.GetActor(rauth)
+	unitsUsed := uf.MaxUnits(r)
+
+	exists, admin, err := storage.GetAdmin(ctx, db)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists || admin != actor {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputNotAdmin}, nil
+	}
+
+	exists, _, err = storage.GetEntityMeta(ctx, db, uf.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+	if !exists {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: OutputEntityNotRegistered}, nil
+	}
+
+	feeders, err := storage.GetEntityFeeders(ctx, db, uf.EntityIndex)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, nil
+	}
+
+	updated := make([]crypto.PublicKey, 0, len(feeders)+1)
+	for _, feeder := range feeders {
+		if feeder != uf.Feeder {
+			updated = append(updated, feeder)
+		}
+	}
+	if uf.Authorized {
+		if len(updated) >= consts.EntityMaxFeeders {
+			return &chain.Result{Success: false, Units: unitsUsed, Output: OutputTooManyFeeders}, nil
+		}
+		updated = append(updated, uf.Feeder)
+	}
+
+	if err := storage.StoreEntityFeeders(ctx, db, uf.EntityIndex, updated); err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+
+	return &chain.Result{Success: true, Units: unitsUsed}, nil
+}
+
+func (uf *UpdateFeeder) MaxUnits(chain.Rules) uint64 {
+	return uint64(uf.Size())
+}
+
+func (*UpdateFeeder) Size() int {
+	return hconsts.Uint64Len + crypto.PublicKeyLen + hconsts.BoolLen
+}
+
+func (uf *UpdateFeeder) Marshal(p *codec.Packer) {
+	p.PackUint64(uf.EntityIndex)
+	p.PackPublicKey(uf.Feeder)
+	p.PackBool(uf.Authorized)
+}
+
+func UnmarshalUpdateFeeder(p *codec.Packer, _ *warp.Message) (chain.Action, error) {
+	var update UpdateFeeder
+
+	// index can be 0
+	update.EntityIndex = p.UnpackUint64(false)
+	p.UnpackPublicKey(true, &update.Feeder)
+	update.Authorized = p.UnpackBool()
+
+	return &update, p.Err()
+}
+
+func (*UpdateFeeder) ValidRange(chain.Rules) (int64, int64) {
+	return -1, -1
+}
@@ -2,12 +2,14 @@ package actions
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/hypersdk/chain"
 	"github.com/ava-labs/hypersdk/codec"
 	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/ava-labs/hypersdk/utils"
 
 	"github.com/bianyuanop/oraclevm/auth"
@@ -28,9 +30,7 @@ func (*UploadEntity) GetTypeID() uint8 {
 }
 
 func (ue *UploadEntity) StateKeys(rauth chain.Auth, txID ids.ID) [][]byte {
-	return [][]byte{
-		storage.PrefixEntityKey(txID),
-	}
+	return submissionStateKeys(txID, ue.EntityIndex, auth.GetActor(rauth))
 }
 
 func (ue *UploadEntity) Execute(
@@ -45,23 +45,165 @@ func (ue *UploadEntity) Execute(
 	actor := auth.GetActor(rauth)
 	unitsUsed := ue.MaxUnits(r)
 
-	if len(ue.Payload) > consts.PayloadMaxLen {
-		return &chain.Result{Success: false, Units: unitsUsed, Output: PayloadSizeTooLarge}, nil
+	entity, output, err := submitEntity(ctx, r, db, t, txID, actor, ue.EntityType, ue.EntityIndex, ue.Payload)
+	if err != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	}
+	if output != nil {
+		return &chain.Result{Success: false, Units: unitsUsed, Output: output}, nil
 	}
 
-	// try marshal payload
-	entity, err := oracle.UnmarshalEntity(ue.EntityType, ue.Payload)
+	output, err = oracle.NewEntityWithMeta(ue.EntityType, ue.EntityIndex, entity).Marshal()
 	if err != nil {
 		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
 	}
 
-	if err := storage.StoreEntity(ctx, db, txID, ue.EntityType, ue.EntityIndex, t, actor, ue.Payload); err != nil {
-		return &chain.Result{Success: false, Units: unitsUsed, Output: utils.ErrBytes(err)}, err
+	return &chain.Result{Success: true, Units: unitsUsed, Output: output}, nil
+}
+
+// submissionStateKeys returns the keys touched by [submitEntity]
+func submissionStateKeys(entityID ids.ID, entityIndex uint64, publisher crypto.PublicKey) [][]byte {
+	return [][]byte{
+		storage.PrefixEntityKey(entityID),
+		storage.PrefixEntityRoundKey(entityIndex),
+		storage.PrefixEntityMetaKey(entityIndex),
+		storage.PrefixEntityFeedersKey(entityIndex),
+		storage.PrefixStakeKey(publisher),
+		storage.PrefixRoundSlotKey(entityIndex, publisher),
+	}
+}
+
+// submitEntity validates [payload] published by [publisher] and submits it to
+// the current round of [entityIndex], the entity is stored under [entityID].
+// [output] explains why the submission is rejected, [err] is only set when
+// state can't be written.
+func submitEntity(
+	ctx context.Context,
+	r chain.Rules,
+	db chain.Database,
+	t int64,
+	entityID ids.ID,
+	publisher crypto.PublicKey,
+	entityType uint64,
+	entityIndex uint64,
+	payload []byte,
+) (entity oracle.Entity, output []byte, err error) {
+	if len(payload) > consts.PayloadMaxLen {
+		return nil, PayloadSizeTooLarge, nil
+	}
+
+	exists, meta, err := storage.GetEntityMeta(ctx, db, entityIndex)
+	if err != nil {
+		return nil, utils.ErrBytes(err), nil
+	}
+	if !exists {
+		return nil, OutputEntityNotRegistered, nil
+	}
+	if meta.EntityType != entityType {
+		return nil, OutputEntityTypeMismatch, nil
+	}
+
+	// payloads are validated against their type and the target collection
+	entity, err = oracle.UnmarshalEntity(entityType, payload)
+	if err != nil {
+		return nil, validationOutput(err), nil
+	}
+	if err := meta.Accepts(entity); err != nil {
+		return nil, validationOutput(err), nil
 	}
 
-	output := oracle.NewEntityWithMeta(ue.EntityType, ue.EntityIndex, entity)
+	feeders, err := storage.GetEntityFeeders(ctx, db, entityIndex)
+	if err != nil {
+		return nil, utils.ErrBytes(err), nil
+	}
+	authorized := false
+	for _, feeder := range feeders {
+		if feeder == publisher {
+			authorized = true
+			break
+		}
+	}
+
+	stake, pending, err := storage.GetStake(ctx, db, publisher)
+	if err != nil {
+		return nil, utils.ErrBytes(err), nil
+	}
+	// publishers staking enough are allowed to upload to any collection
+	if minStake := fetchUint64(r, consts.MinFeederStakeKey); minStake > 0 && stake >= minStake {
+		authorized = true
+	}
+	if !authorized {
+		return nil, OutputUnauthorizedPublisher, nil
+	}
+
+	round, err := storage.GetEntityRound(ctx, db, entityIndex)
+	if err != nil {
+		return nil, utils.ErrBytes(err), nil
+	}
+
+	if len(round.Submissions) == 0 {
+		round.EntityType = entityType
+		round.StartTick = t
+	} else if round.EntityType != entityType {
+		return nil, OutputEntityTypeMismatch, nil
+	}
 
-	return &chain.Result{Success: true, Units: unitsUsed, Output: output.Marshal()}, nil
+	// a publisher holds one submission per round, a later upload replaces
+	// its submission so that repeated uploads can't dominate the result
+	submission := &storage.RoundSubmission{
+		Publisher: publisher,
+		Tick:      t,
+		Payload:   payload,
+	}
+	exists, slotRound, position, err := storage.GetRoundSlot(ctx, db, entityIndex, publisher)
+	if err != nil {
+		return nil, utils.ErrBytes(err), nil
+	}
+	if exists && slotRound == round.Round && position < len(round.Submissions) &&
+		round.Submissions[position].Publisher == publisher {
+		round.Submissions[position] = submission
+	} else {
+		if len(round.Submissions) >= consts.RoundMaxSubmissions {
+			return nil, OutputRoundFull, nil
+		}
+
+		position = len(round.Submissions)
+		round.Submissions = append(round.Submissions, submission)
+		if err := storage.SetRoundSlot(ctx, db, entityIndex, publisher, round.Round, position); err != nil {
+			return nil, nil, err
+		}
+
+		// stake can't be withdrawn before the submission is aggregated
+		if err := storage.SetStake(ctx, db, publisher, stake, pending+1); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := storage.StoreEntityRound(ctx, db, entityIndex, round); err != nil {
+		return nil, nil, err
+	}
+
+	if err := storage.StoreEntity(ctx, db, entityID, entityType, entityIndex, t, publisher, payload); err != nil {
+		return nil, nil, err
+	}
+
+	return entity, nil, nil
+}
+
+// validationOutput structures the output of uploads failing validation,
+// invalid fields are reported as [oracle.FieldError]
+func validationOutput(err error) []byte {
+	var fieldErr *oracle.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return fieldErr.Marshal()
+	case errors.Is(err, oracle.ErrMarshalEntityFailed), errors.Is(err, oracle.ErrLegacyPayload):
+		return OutputMalformedPayload
+	case errors.Is(err, oracle.ErrNotSupportedEntity):
+		return OutputEntityNotSupported
+	default:
+		return utils.ErrBytes(err)
+	}
 }
 
 func (ue *UploadEntity) ValidRange(_ chain.Rules) (int64, int64) {
@@ -4,14 +4,49 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
+
+	oconsts "github.com/bianyuanop/oraclevm/consts"
 )
 
-const WarpQuerySize = consts.Uint64Len + consts.IDLen +
-	consts.Uint64Len /* op bits */
+// size of the query options shared by [WarpQuery] and [BatchWarpQuery]
+const warpQueryOptionsSize = consts.IDLen +
+	consts.Uint64Len /* mode */ + consts.Int64Len /* window */ +
+	consts.Int64Len /* max age */ + consts.Int64Len /* tick */ + consts.Uint64Len /* round */
+
+const WarpQuerySize = consts.Uint64Len + warpQueryOptionsSize
+
+const BatchWarpQueryMaxSize = consts.IntLen + consts.Uint64Len*oconsts.BatchQueryMaxEntities +
+	warpQueryOptionsSize
+
+// query modes selected by [WarpQuery]
+const (
+	// latest aggregation result
+	LatestQueryMode uint64 = 0
+	// time-weighted average of aggregation results over [WarpQuery.Window]
+	TWAPQueryMode uint64 = 1
+	// aggregation result valid at timestamp [WarpQuery.Tick]
+	AtTickQueryMode uint64 = 2
+	// aggregation result valid at round [WarpQuery.Round]
+	AtRoundQueryMode uint64 = 3
+)
 
 type WarpQuery struct {
 	EntityIndex        uint64 `json:"entityIndex"`
 	DestinationChainID ids.ID `json:"destinationChainID"`
+
+	Mode uint64 `json:"mode"`
+	// time window in milliseconds ending at the block timestamp, only used
+	// by [TWAPQueryMode]
+	Window int64 `json:"window"`
+	// max age in milliseconds of the aggregation result at the block
+	// timestamp, at [Tick] for [AtTickQueryMode] or when [Round] closed for
+	// [AtRoundQueryMode], 0 accepts results of any age
+	MaxAge int64 `json:"maxAge"`
+	// point in time of historical queries, only used by [AtTickQueryMode],
+	// the round closed at [Tick] is resolved by the relayer
+	Tick int64 `json:"tick"`
+	// queried round, only used by [AtRoundQueryMode]
+	Round uint64 `json:"round"`
 }
 
 func (w *WarpQuery) Marshal() ([]byte, error) {
@@ -19,6 +54,11 @@ func (w *WarpQuery) Marshal() ([]byte, error) {
 
 	p.PackUint64(w.EntityIndex)
 	p.PackID(w.DestinationChainID)
+	p.PackUint64(w.Mode)
+	p.PackInt64(w.Window)
+	p.PackInt64(w.MaxAge)
+	p.PackInt64(w.Tick)
+	p.PackUint64(w.Round)
 
 	return p.Bytes(), p.Err()
 }
@@ -28,6 +68,123 @@ func UnmarshalWarpQuery(b []byte) (*WarpQuery, error) {
 	p := codec.NewReader(b, WarpQuerySize)
 	query.EntityIndex = p.UnpackUint64(false)
 	p.UnpackID(true, &query.DestinationChainID)
+	query.Mode = p.UnpackUint64(false)
+	query.Window = p.UnpackInt64(false)
+	query.MaxAge = p.UnpackInt64(false)
+	query.Tick = p.UnpackInt64(false)
+	query.Round = p.UnpackUint64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if err := query.verify(); err != nil {
+		return nil, err
+	}
+
+	return &query, nil
+}
+
+func (w *WarpQuery) verify() error {
+	if w.MaxAge < 0 {
+		return ErrInvalidMaxAge
+	}
+
+	switch w.Mode {
+	case LatestQueryMode, AtRoundQueryMode:
+	case TWAPQueryMode:
+		if w.Window <= 0 {
+			return ErrInvalidWindow
+		}
+	case AtTickQueryMode:
+		if w.Tick <= 0 {
+			return ErrInvalidTick
+		}
+	default:
+		return ErrUnknownQueryMode
+	}
+	return nil
+}
+
+// BatchWarpQuery queries several entity collections with the same options
+// in one warp message
+type BatchWarpQuery struct {
+	EntityIndices      []uint64 `json:"entityIndices"`
+	DestinationChainID ids.ID   `json:"destinationChainID"`
+
+	// options applied to every entity, see [WarpQuery]
+	Mode   uint64 `json:"mode"`
+	Window int64  `json:"window"`
+	MaxAge int64  `json:"maxAge"`
+	Tick   int64  `json:"tick"`
+	Round  uint64 `json:"round"`
+}
+
+// Queries expands [b] into one [WarpQuery] per entity
+func (b *BatchWarpQuery) Queries() []*WarpQuery {
+	queries := make([]*WarpQuery, len(b.EntityIndices))
+	for i, entityIndex := range b.EntityIndices {
+		queries[i] = &WarpQuery{
+			EntityIndex:        entityIndex,
+			DestinationChainID: b.DestinationChainID,
+			Mode:               b.Mode,
+			Window:             b.Window,
+			MaxAge:             b.MaxAge,
+			Tick:               b.Tick,
+			Round:              b.Round,
+		}
+	}
+	return queries
+}
+
+func (b *BatchWarpQuery) Marshal() ([]byte, error) {
+	size := consts.IntLen + consts.Uint64Len*len(b.EntityIndices) + warpQueryOptionsSize
+	p := codec.NewWriter(size, BatchWarpQueryMaxSize)
+
+	p.PackInt(len(b.EntityIndices))
+	for _, entityIndex := range b.EntityIndices {
+		p.PackUint64(entityIndex)
+	}
+	p.PackID(b.DestinationChainID)
+	p.PackUint64(b.Mode)
+	p.PackInt64(b.Window)
+	p.PackInt64(b.MaxAge)
+	p.PackInt64(b.Tick)
+	p.PackUint64(b.Round)
+
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalBatchWarpQuery(b []byte) (*BatchWarpQuery, error) {
+	var query BatchWarpQuery
+	p := codec.NewReader(b, BatchWarpQueryMaxSize)
+	count := p.UnpackInt(false)
+	if count > oconsts.BatchQueryMaxEntities {
+		return nil, ErrInvalidBatchSize
+	}
+	seen := make(map[uint64]struct{}, count)
+	for i := 0; i < count; i++ {
+		entityIndex := p.UnpackUint64(false)
+		if _, ok := seen[entityIndex]; ok {
+			return nil, ErrDuplicateEntityIndex
+		}
+		seen[entityIndex] = struct{}{}
+		query.EntityIndices = append(query.EntityIndices, entityIndex)
+	}
+	p.UnpackID(true, &query.DestinationChainID)
+	query.Mode = p.UnpackUint64(false)
+	query.Window = p.UnpackInt64(false)
+	query.MaxAge = p.UnpackInt64(false)
+	query.Tick = p.UnpackInt64(false)
+	query.Round = p.UnpackUint64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if len(query.EntityIndices) == 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	// options are shared by all entities
+	if err := query.Queries()[0].verify(); err != nil {
+		return nil, err
+	}
 
 	return &query, nil
 }
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"github.com/ava-labs/hypersdk/codec"
+	"github.com/ava-labs/hypersdk/consts"
+)
+
+const WarpSubscriptionSize = consts.Uint64Len*2 + consts.Int64Len*2
+
+// WarpSubscription is sent by a chain to subscribe to pushes of aggregation
+// results of [EntityIndex], see [Subscribe]. Both thresholds set to 0 cancel
+// the subscription.
+type WarpSubscription struct {
+	EntityIndex uint64 `json:"entityIndex"`
+
+	Deviation uint64 `json:"deviation"`
+	Heartbeat int64  `json:"heartbeat"`
+	// timestamp of the request, requests are only accepted within the
+	// validity window of transactions around [Tick] and when newer than the
+	// request the subscription was last updated by, so that relayers can't
+	// replay them
+	Tick int64 `json:"tick"`
+}
+
+func (w *WarpSubscription) Marshal() ([]byte, error) {
+	p := codec.NewWriter(WarpSubscriptionSize, WarpSubscriptionSize)
+
+	p.PackUint64(w.EntityIndex)
+	p.PackUint64(w.Deviation)
+	p.PackInt64(w.Heartbeat)
+	p.PackInt64(w.Tick)
+
+	return p.Bytes(), p.Err()
+}
+
+func UnmarshalWarpSubscription(b []byte) (*WarpSubscription, error) {
+	var subscription WarpSubscription
+	p := codec.NewReader(b, WarpSubscriptionSize)
+	subscription.EntityIndex = p.UnpackUint64(false)
+	subscription.Deviation = p.UnpackUint64(false)
+	subscription.Heartbeat = p.UnpackInt64(false)
+	subscription.Tick = p.UnpackInt64(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if subscription.Heartbeat < 0 {
+		return nil, ErrInvalidHeartbeat
+	}
+	if subscription.Tick <= 0 {
+		return nil, ErrInvalidTick
+	}
+
+	return &subscription, nil
+}
@@ -5,10 +5,18 @@ package cmd
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/hypersdk/crypto"
+	hutils "github.com/ava-labs/hypersdk/utils"
 	"github.com/bianyuanop/oraclevm/actions"
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -75,21 +83,27 @@ var uploadCmd = &cobra.Command{
 			return err
 		}
 
-		entityType, err := handler.Root().PromptChoice("type", 1)
+		entityType, err := handler.Root().PromptChoice("type", 3)
 		if err != nil {
 			return err
 		}
 
-		payload, err := handler.Root().PromptString("payload", 0, 500)
+		data, err := handler.Root().PromptString("payload", 0, 500)
 
 		if err != nil {
 			return err
 		}
 
+		// entities are uploaded in their binary encoding
+		payload, err := oracle.EncodeJSONEntity(uint64(entityType), []byte(data))
+		if err != nil {
+			return err
+		}
+
 		_, _, err = sendAndWait(ctx, nil, &actions.UploadEntity{
 			EntityIndex: uint64(entityIndex),
 			EntityType:  uint64(entityType),
-			Payload:     []byte(payload),
+			Payload:     payload,
 		}, cli, bcli, factory, true)
 
 		return err
@@ -110,9 +124,90 @@ var queryCmd = &cobra.Command{
 			return err
 		}
 
+		// results are delivered to the destination chain by relayers
+		destinationChainID, err := handler.Root().PromptID("destination chainID")
+		if err != nil {
+			return err
+		}
+
 		warpQuery := actions.WarpQuery{
 			EntityIndex:        uint64(entityIndex),
-			DestinationChainID: ids.GenerateTestID(),
+			DestinationChainID: destinationChainID,
+		}
+		if err := promptQueryOptions(&warpQuery); err != nil {
+			return err
+		}
+
+		payload, err := warpQuery.Marshal()
+		if err != nil {
+			return err
+		}
+
+		uwm, err := warp.NewUnsignedMessage(uint32(1), ids.Empty, payload)
+		if err != nil {
+			return err
+		}
+		wm, err := warp.NewMessage(uwm, &warp.BitSetSignature{})
+		if err != nil {
+			return err
+		}
+
+		// time queries are answered by the latest round closed at the tick
+		query := &actions.Query{}
+		if warpQuery.Mode == actions.AtTickQueryMode {
+			query.Round, err = bcli.RoundAt(ctx, warpQuery.EntityIndex, warpQuery.Tick)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, _, err = sendAndWait(ctx, wm, query, cli, bcli, factory, true)
+
+		return err
+	},
+}
+
+var batchQueryCmd = &cobra.Command{
+	Use: "batch_query",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		rawIndices, err := handler.Root().PromptString("indices (comma separated)", 1, 512)
+		if err != nil {
+			return err
+		}
+		entityIndices := []uint64{}
+		for _, rawIndex := range strings.Split(rawIndices, ",") {
+			entityIndex, err := strconv.ParseUint(strings.TrimSpace(rawIndex), 10, 64)
+			if err != nil {
+				return err
+			}
+			entityIndices = append(entityIndices, entityIndex)
+		}
+
+		// results are delivered to the destination chain by relayers
+		destinationChainID, err := handler.Root().PromptID("destination chainID")
+		if err != nil {
+			return err
+		}
+
+		// options are shared by all queried entities
+		var options actions.WarpQuery
+		if err := promptQueryOptions(&options); err != nil {
+			return err
+		}
+		warpQuery := actions.BatchWarpQuery{
+			EntityIndices:      entityIndices,
+			DestinationChainID: destinationChainID,
+			Mode:               options.Mode,
+			Window:             options.Window,
+			MaxAge:             options.MaxAge,
+			Tick:               options.Tick,
+			Round:              options.Round,
 		}
 
 		payload, err := warpQuery.Marshal()
@@ -129,8 +224,347 @@ var queryCmd = &cobra.Command{
 			return err
 		}
 
-		_, _, err = sendAndWait(ctx, wm, &actions.Query{}, cli, bcli, factory, true)
+		// time queries are answered by the latest round closed at the tick of
+		// every entity
+		query := &actions.BatchQuery{}
+		if warpQuery.Mode == actions.AtTickQueryMode {
+			for _, entityIndex := range entityIndices {
+				round, err := bcli.RoundAt(ctx, entityIndex, warpQuery.Tick)
+				if err != nil {
+					return err
+				}
+				query.Rounds = append(query.Rounds, round)
+			}
+		}
+
+		_, _, err = sendAndWait(ctx, wm, query, cli, bcli, factory, true)
+
+		return err
+	},
+}
+
+// promptQueryOptions prompts the mode of [warpQuery] and the options used by
+// that mode
+func promptQueryOptions(warpQuery *actions.WarpQuery) error {
+	// 0 queries the latest result, 1 the time-weighted average, 2 and 3 the
+	// result at a timestamp or round
+	mode, err := handler.Root().PromptChoice("mode", 4)
+	if err != nil {
+		return err
+	}
+	warpQuery.Mode = uint64(mode)
+
+	switch warpQuery.Mode {
+	case actions.TWAPQueryMode:
+		window, err := handler.Root().PromptInt("window (ms)")
+		if err != nil {
+			return err
+		}
+		warpQuery.Window = int64(window)
+	case actions.AtTickQueryMode:
+		tick, err := handler.Root().PromptInt("tick (ms)")
+		if err != nil {
+			return err
+		}
+		warpQuery.Tick = int64(tick)
+	case actions.AtRoundQueryMode:
+		round, err := handler.Root().PromptInt("round")
+		if err != nil {
+			return err
+		}
+		warpQuery.Round = uint64(round)
+	}
+
+	limitAge, err := handler.Root().PromptBool("limit age")
+	if err != nil {
+		return err
+	}
+	if limitAge {
+		maxAge, err := handler.Root().PromptInt("max age (ms)")
+		if err != nil {
+			return err
+		}
+		warpQuery.MaxAge = int64(maxAge)
+	}
+	return nil
+}
+
+var aggregateCmd = &cobra.Command{
+	Use: "aggregate",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		entityIndex, err := handler.Root().PromptChoice("index", 1)
+		if err != nil {
+			return err
+		}
+
+		// stake of every publisher in the round is settled on aggregation
+		round, err := bcli.Round(ctx, uint64(entityIndex))
+		if err != nil {
+			return err
+		}
+		publishers := make([]crypto.PublicKey, len(round.Publishers))
+		for i, addr := range round.Publishers {
+			publishers[i], err = utils.ParseAddress(addr)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.Aggregate{
+			EntityIndex: uint64(entityIndex),
+			Round:       round.Round,
+			Publishers:  publishers,
+		}, cli, bcli, factory, true)
+
+		return err
+	},
+}
+
+var registerEntityCmd = &cobra.Command{
+	Use: "register_entity",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		// new collection takes the next index
+		metas, err := bcli.AvailableEntities(ctx)
+		if err != nil {
+			return err
+		}
+
+		name, err := handler.Root().PromptString("name", 1, consts.EntityNameMaxLen)
+		if err != nil {
+			return err
+		}
+
+		entityType, err := handler.Root().PromptChoice("type", 3)
+		if err != nil {
+			return err
+		}
+
+		aggregator, err := handler.Root().PromptChoice("aggregator", 5)
+		if err != nil {
+			return err
+		}
+
+		// e.g. {"maxDeviation":1000}, empty disables the outlier filter
+		params, err := handler.Root().PromptString("params", 0, consts.EntityParamsMaxLen)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.RegisterEntity{
+			EntityIndex: uint64(len(metas)),
+			EntityName:  name,
+			EntityType:  uint64(entityType),
+			Aggregator:  uint64(aggregator),
+			Params:      []byte(params),
+		}, cli, bcli, factory, true)
+
+		return err
+	},
+}
+
+var updateFeederCmd = &cobra.Command{
+	Use: "update_feeder",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		entityIndex, err := handler.Root().PromptChoice("index", 1)
+		if err != nil {
+			return err
+		}
+
+		feeder, err := handler.Root().PromptAddress("feeder")
+		if err != nil {
+			return err
+		}
+
+		authorized, err := handler.Root().PromptBool("authorize")
+		if err != nil {
+			return err
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.UpdateFeeder{
+			EntityIndex: uint64(entityIndex),
+			Feeder:      feeder,
+			Authorized:  authorized,
+		}, cli, bcli, factory, true)
+
+		return err
+	},
+}
+
+var stakeCmd = &cobra.Command{
+	Use: "stake",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, priv, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		balance, err := handler.GetBalance(ctx, bcli, priv.PublicKey())
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		amount, err := handler.Root().PromptAmount("amount", ids.Empty, balance, nil)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.Stake{
+			Amount: amount,
+		}, cli, bcli, factory, true)
+		return err
+	},
+}
+
+var unstakeCmd = &cobra.Command{
+	Use: "unstake",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, priv, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		stake, pending, err := bcli.Stake(ctx, utils.Address(priv.PublicKey()))
+		if err != nil {
+			return err
+		}
+		hutils.Outf("{{yellow}}stake:{{/}} %d {{yellow}}pending submissions:{{/}} %d\n", stake, pending)
+		if stake == 0 {
+			return nil
+		}
+
+		amount, err := handler.Root().PromptAmount("amount", ids.Empty, stake, nil)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.Unstake{
+			Amount: amount,
+		}, cli, bcli, factory, true)
+		return err
+	},
+}
+
+var fundQueriesCmd = &cobra.Command{
+	Use: "fund_queries",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, priv, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		chainID, err := handler.Root().PromptID("querying chainID")
+		if err != nil {
+			return err
+		}
+		prepaid, err := bcli.QueryBalance(ctx, chainID)
+		if err != nil {
+			return err
+		}
+		hutils.Outf("{{yellow}}query balance:{{/}} %d\n", prepaid)
+
+		balance, err := handler.GetBalance(ctx, bcli, priv.PublicKey())
+		if balance == 0 || err != nil {
+			return err
+		}
+
+		amount, err := handler.Root().PromptAmount("amount", ids.Empty, balance, nil)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = sendAndWait(ctx, nil, &actions.FundQueries{
+			ChainID: chainID,
+			Amount:  amount,
+		}, cli, bcli, factory, true)
+		return err
+	},
+}
+
+var subscribeCmd = &cobra.Command{
+	Use: "subscribe",
+	RunE: func(*cobra.Command, []string) error {
+		ctx := context.Background()
+		_, _, factory, cli, bcli, err := handler.DefaultActor()
+		if err != nil {
+			return err
+		}
+
+		entityIndex, err := handler.Root().PromptChoice("index", 1)
+		if err != nil {
+			return err
+		}
+
+		// subscriptions are requested by the destination chain, results are
+		// pushed to it by relayers
+		destinationChainID, err := handler.Root().PromptID("destination chainID")
+		if err != nil {
+			return err
+		}
+
+		// leaving both thresholds unset cancels the subscription
+		subscribe := &actions.WarpSubscription{
+			EntityIndex: uint64(entityIndex),
+			Tick:        time.Now().UnixMilli(),
+		}
+		setDeviation, err := handler.Root().PromptBool("push on deviation")
+		if err != nil {
+			return err
+		}
+		if setDeviation {
+			deviation, err := handler.Root().PromptInt("deviation (bps)")
+			if err != nil {
+				return err
+			}
+			subscribe.Deviation = uint64(deviation)
+		}
+		setHeartbeat, err := handler.Root().PromptBool("push on heartbeat")
+		if err != nil {
+			return err
+		}
+		if setHeartbeat {
+			heartbeat, err := handler.Root().PromptInt("heartbeat (ms)")
+			if err != nil {
+				return err
+			}
+			subscribe.Heartbeat = int64(heartbeat)
+		}
+
+		payload, err := subscribe.Marshal()
+		if err != nil {
+			return err
+		}
+
+		uwm, err := warp.NewUnsignedMessage(uint32(1), destinationChainID, payload)
+		if err != nil {
+			return err
+		}
+		wm, err := warp.NewMessage(uwm, &warp.BitSetSignature{})
+		if err != nil {
+			return err
+		}
 
+		_, _, err = sendAndWait(ctx, wm, &actions.Subscribe{}, cli, bcli, factory, true)
 		return err
 	},
 }
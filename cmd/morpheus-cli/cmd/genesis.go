@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"os"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
@@ -53,6 +54,26 @@ var genGenesisCmd = &cobra.Command{
 			return err
 		}
 		g.CustomAllocation = allocs
+		g.Admin = admin
+		for _, rawChainID := range queryChains {
+			chainID, err := ids.FromString(rawChainID)
+			if err != nil {
+				return err
+			}
+			g.QueryChains = append(g.QueryChains, chainID)
+		}
+
+		if len(entitiesFile) > 0 {
+			e, err := os.ReadFile(entitiesFile)
+			if err != nil {
+				return err
+			}
+			entities := []*genesis.EntityRegistration{}
+			if err := json.Unmarshal(e, &entities); err != nil {
+				return err
+			}
+			g.Entities = entities
+		}
 
 		b, err := json.Marshal(g)
 		if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/bianyuanop/oraclevm/actions"
 	"github.com/bianyuanop/oraclevm/auth"
 	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
 	brpc "github.com/bianyuanop/oraclevm/rpc"
 	tutils "github.com/bianyuanop/oraclevm/utils"
 )
@@ -55,6 +56,19 @@ func handleTx(tx *chain.Transaction, result *chain.Result) {
 		switch action := tx.Action.(type) { //nolint:gocritic
 		case *actions.Transfer:
 			summaryStr = fmt.Sprintf("%s %s -> %s", utils.FormatBalance(action.Value), consts.Symbol, tutils.Address(action.To))
+		case *actions.UploadEntity, *actions.Aggregate:
+			// entities are output in their binary encoding
+			if ewm, err := oracle.UnmarshalEntityWithMeta(result.Output); err == nil {
+				summaryStr = oracle.EntityName(ewm.ID, ewm.Type)
+				if ewm.Entity != nil {
+					summaryStr += ": " + oracle.Render(ewm.Entity)
+				}
+				if ewm.Stale {
+					summaryStr += " (stale)"
+				}
+			}
+		case *actions.SubmitReport:
+			summaryStr = fmt.Sprintf("%d observations signed by %d feeders", len(action.Observations), len(action.Signatures))
 		}
 	}
 	utils.Outf(
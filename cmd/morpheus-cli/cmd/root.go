@@ -24,6 +24,9 @@ var (
 
 	dbPath            string
 	genesisFile       string
+	entitiesFile      string
+	admin             string
+	queryChains       []string
 	minUnitPrice      int64
 	maxBlockUnits     int64
 	windowTargetUnits int64
@@ -80,6 +83,24 @@ func init() {
 		defaultGenesis,
 		"genesis file path",
 	)
+	genGenesisCmd.PersistentFlags().StringVar(
+		&entitiesFile,
+		"entities-file",
+		"",
+		"entity collections registered at genesis",
+	)
+	genGenesisCmd.PersistentFlags().StringVar(
+		&admin,
+		"admin",
+		"",
+		"address registering entity collections and managing their feeders",
+	)
+	genGenesisCmd.PersistentFlags().StringSliceVar(
+		&queryChains,
+		"query-chains",
+		nil,
+		"chains allowed to query by warp messages",
+	)
 	genGenesisCmd.PersistentFlags().Int64Var(
 		&minUnitPrice,
 		"min-unit-price",
@@ -143,6 +164,14 @@ func init() {
 		transferCmd,
 		uploadCmd,
 		queryCmd,
+		batchQueryCmd,
+		aggregateCmd,
+		registerEntityCmd,
+		updateFeederCmd,
+		stakeCmd,
+		unstakeCmd,
+		fundQueriesCmd,
+		subscribeCmd,
 	)
 
 	// spam
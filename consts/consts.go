@@ -22,6 +22,53 @@ const (
 	PayloadMaxLen   = 1024
 	HistoryCacheLen = 500
 	HistoryPurgeLen = 200
+
+	// max number of entities submitted to one collection before aggregation
+	RoundMaxSubmissions = 64
+
+	// bounds of entity collection registration
+	EntityNameMaxLen   = 64
+	EntityParamsMaxLen = 256
+
+	// max length of text fields of uploaded entities, e.g. tickers
+	EntityFieldMaxLen = 64
+
+	// bounds of numeric feeds
+	NumericFeedMaxDecimals = 18
+	NumericFeedUnitMaxLen  = 16
+
+	// max number of publishers authorized to upload to one collection
+	EntityMaxFeeders = 64
+
+	// max number of aggregation results of one collection observed in state
+	// to compute time-weighted averages
+	ObservationsMaxLen = 128
+
+	// max number of entity collections queried by one batch warp query
+	BatchQueryMaxEntities = 16
+
+	// max number of chains subscribed to pushes of one collection
+	EntityMaxSubscriptions = 16
+
+	// bounds of reports transmitted on behalf of feeders
+	ReportMaxSigners      = 16
+	ReportMaxObservations = 64
+
+	// max number of reports submitted to one aggregation round, reports are
+	// kept with the round to reject replays
+	RoundMaxReports = 64
+
+	// deviations and ratios are measured in basis points
+	BasisPoints = 10_000
+)
+
+// keys of custom rules served by [chain.Rules.FetchCustom]
+const (
+	MinFeederStakeKey = "minFeederStake"
+	SlashingBandKey   = "slashingBand"
+	SlashingRatioKey  = "slashingRatio"
+	RoundRewardKey    = "roundReward"
+	QueryChainsKey    = "queryChains"
 )
 
 var ID ids.ID
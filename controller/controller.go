@@ -6,7 +6,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"time"
 
 	ametrics "github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/database"
@@ -14,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/hypersdk/builder"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/ava-labs/hypersdk/gossiper"
 	hrpc "github.com/ava-labs/hypersdk/rpc"
 	hstorage "github.com/ava-labs/hypersdk/storage"
@@ -21,6 +21,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/bianyuanop/oraclevm/actions"
+	"github.com/bianyuanop/oraclevm/auth"
 	"github.com/bianyuanop/oraclevm/config"
 	"github.com/bianyuanop/oraclevm/consts"
 	"github.com/bianyuanop/oraclevm/genesis"
@@ -45,6 +46,8 @@ type Controller struct {
 	metaDB database.Database
 
 	oracle *oracle.Oracle
+	// set once pending entities are restored from state
+	oracleSynced bool
 }
 
 func New() *vm.VM {
@@ -135,8 +138,17 @@ func (c *Controller) Initialize(
 		gossip = gossiper.NewProposer(inner, gcfg)
 	}
 
-	// TODO: not sure if `time.Now().Unix()` is safe to be used here
-	c.oracle = oracle.NewOracle(c, time.Now().Unix(), c.config.TrackedStocks)
+	// collection windows are moved by the block timestamps of accepted entities
+	c.oracle, err = oracle.NewOracle(c, 0, c.genesis.EntityMetas())
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+	if err := c.restoreOracle(); err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf(
+			"unable to restore oracle: %w",
+			err,
+		)
+	}
 
 	return c.config, c.genesis, build, gossip, blockDB, stateDB, apis, consts.ActionRegistry, consts.AuthRegistry, nil
 }
@@ -171,50 +183,110 @@ func (c *Controller) Accepted(ctx context.Context, blk *chain.StatelessBlock) er
 			}
 		}
 		if result.Success {
-			switch tx.Action.(type) { //nolint:gocritic
+			switch action := tx.Action.(type) { //nolint:gocritic
 			case *actions.Transfer:
 				c.metrics.transfer.Inc()
 			case *actions.UploadEntity:
 				c.metrics.upload.Inc()
-				entityWithMeta, err := oracle.UnmarshalEntityWithMeta(result.Output)
+				entity, err := oracle.RestoreEntity(action.EntityType, auth.GetActor(tx.Auth), blk.GetTimestamp(), action.Payload)
 				if err != nil {
 					return err
 				}
 
 				c.Logger().Debug("UploadEntity Triggered")
 				c.Logger().Debug(string(result.Output))
-				c.oracle.InsertEntity(entityWithMeta.ID, entityWithMeta.Type, entityWithMeta.Entity)
+				c.oracle.InsertEntity(action.EntityIndex, action.EntityType, entity)
+
+			case *actions.SubmitReport:
+				c.metrics.report.Inc()
+				// observations are published by their signers
+				for _, obs := range action.Observations {
+					signer := action.Signatures[obs.Signer].Signer
+					entity, err := oracle.RestoreEntity(obs.EntityType, signer, blk.GetTimestamp(), obs.Payload)
+					if err != nil {
+						return err
+					}
+					c.oracle.InsertEntity(obs.EntityIndex, obs.EntityType, entity)
+				}
 
 			case *actions.Query:
 				c.metrics.query.Inc()
+			case *actions.BatchQuery:
+				c.metrics.batch.Inc()
+			case *actions.FundQueries:
+				c.metrics.fund.Inc()
+			case *actions.Subscribe:
+				c.metrics.subscribe.Inc()
+			case *actions.RegisterEntity:
+				c.metrics.register.Inc()
+				meta := action.Meta()
+				if err := storage.StoreEntityRegistration(ctx, batch, meta); err != nil {
+					return err
+				}
+				// may already be restored from state ahead of this block
+				if meta.EntityID >= c.oracle.Counter() {
+					if err := c.oracle.AddEntityCollection(meta); err != nil {
+						return err
+					}
+				}
+			case *actions.UpdateFeeder:
+				c.metrics.feeder.Inc()
+			case *actions.Stake:
+				c.metrics.stake.Inc()
+			case *actions.Unstake:
+				c.metrics.unstake.Inc()
+			case *actions.Aggregate:
+				c.metrics.aggregate.Inc()
+				entityWithMeta, err := oracle.UnmarshalEntityWithMeta(result.Output)
+				if err != nil {
+					return err
+				}
+
+				// rounds closed without quorum publish no result
+				if entityWithMeta.Stale {
+					if err := c.oracle.DiscardPendingEntities(action.EntityIndex); err != nil {
+						c.Logger().Debug(fmt.Sprintf("entity %d is not tracked by this node: %+v", action.EntityIndex, err))
+					}
+					continue
+				}
+
+				// aggregation results are stamped with the block timestamp
+				payload, err := entityWithMeta.Entity.Marshal()
+				if err != nil {
+					return err
+				}
+				entity, err := oracle.RestoreEntity(entityWithMeta.Type, crypto.EmptyPublicKey, blk.GetTimestamp(), payload)
+				if err != nil {
+					return err
+				}
+
+				if err := storage.StoreAggregationResult(
+					ctx,
+					batch,
+					entityWithMeta.Type,
+					action.EntityIndex,
+					blk.GetTimestamp(),
+					payload,
+				); err != nil {
+					return err
+				}
+
+				if err := c.oracle.SaveAggregationResult(action.EntityIndex, entity); err != nil {
+					c.Logger().Debug(fmt.Sprintf("entity %d is not tracked by this node: %+v", action.EntityIndex, err))
+				}
 			}
 		}
 	}
 
-	// store aggregation result
-	for i := 0; i < int(c.oracle.Counter()); i++ {
-		aggretationResult, err := c.oracle.GetAggregatedResult(uint64(i))
-		if err != nil {
-			c.Logger().Debug(fmt.Sprintf("entity %d has an emtpy record for this block: %+v", i, err))
-			continue
+	// state is not readable until the node is ready, retry on next block
+	if !c.oracleSynced {
+		if err := c.syncOracleFromState(ctx, batch, blk.GetTimestamp()); err != nil {
+			c.Logger().Debug(fmt.Sprintf("unable to sync oracle from state: %+v", err))
+		} else {
+			c.oracleSynced = true
 		}
-
-		entityIndex, entityType, err := c.oracle.GetEntityMeta(uint64(i))
-		// should never happen
-		if err != nil {
-			continue
-		}
-		c.Logger().Debug(fmt.Sprintf("%+v", aggretationResult))
-		storage.StoreAggregationResult(ctx, batch, entityType, entityIndex, blk.GetTimestamp(), aggretationResult.Marshal())
-		storage.CacheAggregationResult(ctx, batch, entityType, entityIndex, blk.GetTimestamp(), aggretationResult.Marshal())
 	}
 
-	// c.oracle.ClearEntityCollection()
-	c.oracle.ClearOracleNSaveHistory()
-
-	count, _ := c.oracle.GetEntityCollectionCount(0)
-	c.Logger().Debug(fmt.Sprintf("%+d", count))
-
 	return batch.Write()
 }
 
@@ -11,9 +11,18 @@ import (
 )
 
 type metrics struct {
-	transfer prometheus.Counter
-	upload   prometheus.Counter
-	query    prometheus.Counter
+	transfer  prometheus.Counter
+	upload    prometheus.Counter
+	query     prometheus.Counter
+	aggregate prometheus.Counter
+	register  prometheus.Counter
+	feeder    prometheus.Counter
+	stake     prometheus.Counter
+	unstake   prometheus.Counter
+	batch     prometheus.Counter
+	fund      prometheus.Counter
+	subscribe prometheus.Counter
+	report    prometheus.Counter
 }
 
 func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
@@ -33,11 +42,67 @@ func newMetrics(gatherer ametrics.MultiGatherer) (*metrics, error) {
 			Name:      "query",
 			Help:      "number of query actions",
 		}),
+		aggregate: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "aggregate",
+			Help:      "number of aggregate actions",
+		}),
+		register: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "register_entity",
+			Help:      "number of register entity actions",
+		}),
+		feeder: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "update_feeder",
+			Help:      "number of update feeder actions",
+		}),
+		stake: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "stake",
+			Help:      "number of stake actions",
+		}),
+		unstake: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "unstake",
+			Help:      "number of unstake actions",
+		}),
+		batch: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "batch_query",
+			Help:      "number of batch query actions",
+		}),
+		fund: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "fund_queries",
+			Help:      "number of fund queries actions",
+		}),
+		subscribe: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "subscribe",
+			Help:      "number of subscribe actions",
+		}),
+		report: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "actions",
+			Name:      "submit_report",
+			Help:      "number of submit report actions",
+		}),
 	}
 	r := prometheus.NewRegistry()
 	errs := wrappers.Errs{}
 	errs.Add(
 		r.Register(m.transfer),
+		r.Register(m.upload),
+		r.Register(m.query),
+		r.Register(m.aggregate),
+		r.Register(m.register),
+		r.Register(m.feeder),
+		r.Register(m.stake),
+		r.Register(m.unstake),
+		r.Register(m.batch),
+		r.Register(m.fund),
+		r.Register(m.subscribe),
+		r.Register(m.report),
 
 		gatherer.Register(consts.Name, r),
 	)
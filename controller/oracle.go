@@ -0,0 +1,135 @@
+// Copyright (C) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/storage"
+)
+
+// restoreOracle reloads entity collections and aggregation history persisted
+// in [metaDB] on previous runs
+func (c *Controller) restoreOracle() error {
+	if err := storage.IterateEntityRegistrations(c.metaDB, func(meta *oracle.EntityCollectionMeta) error {
+		// collections registered at genesis are already tracked
+		if meta.EntityID < c.oracle.Counter() {
+			return nil
+		}
+		return c.oracle.AddEntityCollection(meta)
+	}); err != nil {
+		return err
+	}
+
+	// results persisted as JSON by previous versions are re-encoded in their
+	// binary encoding
+	migrated := c.metaDB.NewBatch()
+	if err := storage.IterateAggregationResults(c.metaDB, func(entityIndex uint64, entityType uint64, tick int64, payload []byte) error {
+		entity, err := oracle.MigrateEntity(entityType, crypto.EmptyPublicKey, tick, payload)
+		if err != nil {
+			return err
+		}
+		if oracle.IsLegacyPayload(payload) {
+			payload, err := entity.Marshal()
+			if err != nil {
+				return err
+			}
+			if err := storage.StoreAggregationResult(context.TODO(), migrated, entityType, entityIndex, tick, payload); err != nil {
+				return err
+			}
+		}
+
+		if err := c.oracle.RestoreAggregationResult(entityIndex, entityType, entity); err != nil {
+			c.Logger().Debug(fmt.Sprintf("entity %d is not tracked by this node: %+v", entityIndex, err))
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return migrated.Write()
+}
+
+// syncOracleFromState restores entity collections, pending entities and the
+// latest aggregation result of each collection from state, which is the only
+// source available after state sync. Entries newer than [t] are skipped as
+// they will be delivered by following accepted blocks.
+func (c *Controller) syncOracleFromState(ctx context.Context, batch database.KeyValueWriter, t int64) error {
+	counter, err := storage.GetEntityCounterFromState(ctx, c.inner.ReadState)
+	if err != nil {
+		return err
+	}
+
+	for i := c.oracle.Counter(); i < counter; i++ {
+		exists, meta, err := storage.GetEntityMetaFromState(ctx, c.inner.ReadState, i)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			break
+		}
+		if err := storage.StoreEntityRegistration(ctx, batch, meta); err != nil {
+			return err
+		}
+		if err := c.oracle.AddEntityCollection(meta); err != nil {
+			return err
+		}
+	}
+
+	var i uint64
+	for i = 0; i < c.oracle.Counter(); i++ {
+		entityIndex, entityType, err := c.oracle.GetEntityMeta(i)
+		if err != nil {
+			return err
+		}
+
+		round, err := storage.GetEntityRoundFromState(ctx, c.inner.ReadState, entityIndex)
+		if err != nil {
+			return err
+		}
+
+		if round.EntityType == entityType {
+			entities := make([]oracle.Entity, 0, len(round.Submissions))
+			for _, s := range round.Submissions {
+				if s.Tick > t {
+					continue
+				}
+
+				entity, err := oracle.RestoreEntity(round.EntityType, s.Publisher, s.Tick, s.Payload)
+				if err != nil {
+					return err
+				}
+				entities = append(entities, entity)
+			}
+
+			if err := c.oracle.RestorePendingEntities(entityIndex, entityType, entities); err != nil {
+				return err
+			}
+		}
+
+		exists, cache, err := storage.GetCachedAggregationResultFromState(ctx, c.inner.ReadState, entityIndex)
+		if err != nil {
+			return err
+		}
+		if !exists || cache.EntityType != entityType || cache.Tick > t {
+			continue
+		}
+
+		entity, err := oracle.RestoreEntity(cache.EntityType, crypto.EmptyPublicKey, cache.Tick, cache.Payload)
+		if err != nil {
+			return err
+		}
+		if err := c.oracle.RestoreAggregationResult(entityIndex, entityType, entity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
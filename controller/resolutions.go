@@ -5,6 +5,7 @@ package controller
 
 import (
 	"context"
+	"sort"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
@@ -49,6 +50,117 @@ func (c *Controller) GetAvailableEntities() ([]*oracle.EntityCollectionMeta, err
 	return c.oracle.GetAvailableEntities(), nil
 }
 
+func (c *Controller) GetFeedersFromState(
+	ctx context.Context,
+	entityIndex uint64,
+) ([]crypto.PublicKey, error) {
+	return storage.GetEntityFeedersFromState(ctx, c.inner.ReadState, entityIndex)
+}
+
+func (c *Controller) GetStakeFromState(
+	ctx context.Context,
+	pk crypto.PublicKey,
+) (uint64, uint64, error) {
+	return storage.GetStakeFromState(ctx, c.inner.ReadState, pk)
+}
+
+func (c *Controller) GetEntityRoundFromState(
+	ctx context.Context,
+	entityIndex uint64,
+) (*storage.EntityRound, error) {
+	return storage.GetEntityRoundFromState(ctx, c.inner.ReadState, entityIndex)
+}
+
+func (c *Controller) GetRewardFromState(
+	ctx context.Context,
+	pk crypto.PublicKey,
+) (uint64, uint64, error) {
+	return storage.GetRewardFromState(ctx, c.inner.ReadState, pk)
+}
+
+func (c *Controller) GetRewardPoolFromState(ctx context.Context) (uint64, error) {
+	return storage.GetRewardPoolFromState(ctx, c.inner.ReadState)
+}
+
+func (c *Controller) GetQueryBalanceFromState(ctx context.Context, chainID ids.ID) (uint64, error) {
+	return storage.GetQueryBalanceFromState(ctx, c.inner.ReadState, chainID)
+}
+
+func (c *Controller) GetSubscriptionsFromState(
+	ctx context.Context,
+	entityIndex uint64,
+) ([]*storage.Subscription, error) {
+	return storage.GetSubscriptionsFromState(ctx, c.inner.ReadState, entityIndex)
+}
+
+// GetTWAPFromState returns the time-weighted average of aggregation results of
+// [entityIndex] over [window] milliseconds ending at [t]
+func (c *Controller) GetTWAPFromState(
+	ctx context.Context,
+	entityIndex uint64,
+	window int64,
+	t int64,
+) (uint64, oracle.Entity, error) {
+	exists, cache, err := storage.GetCachedAggregationResultFromState(ctx, c.inner.ReadState, entityIndex)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !exists {
+		return 0, nil, oracle.ErrNoObservations
+	}
+	entityType := cache.EntityType
+	latest, err := oracle.RestoreEntity(entityType, crypto.EmptyPublicKey, cache.Tick, cache.Payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	observations, err := storage.GetObservationsFromState(ctx, c.inner.ReadState, entityIndex)
+	if err != nil {
+		return 0, nil, err
+	}
+	twap, err := oracle.TimeWeighted(entityType, latest, observations, t, window)
+	return entityType, twap, err
+}
+
+// LastAcceptedTimestamp returns the timestamp of the last accepted block
+func (c *Controller) LastAcceptedTimestamp() int64 {
+	return c.inner.LastAcceptedBlock().Tmstmp
+}
+
+// GetRoundAtFromState returns the latest round of [entityIndex] closed no later
+// than [tick], rounds close in order so their close ticks are binary searched
+func (c *Controller) GetRoundAtFromState(
+	ctx context.Context,
+	entityIndex uint64,
+	tick int64,
+) (bool, uint64, error) {
+	round, err := storage.GetEntityRoundFromState(ctx, c.inner.ReadState, entityIndex)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// first round closed after [tick]
+	var searchErr error
+	after := sort.Search(int(round.Round), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		exists, closed, _, err := storage.GetAggregationHistoryFromState(ctx, c.inner.ReadState, entityIndex, uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return !exists || closed > tick
+	})
+	if searchErr != nil {
+		return false, 0, searchErr
+	}
+	if after == 0 {
+		return false, 0, nil
+	}
+	return true, uint64(after - 1), nil
+}
+
 func (c *Controller) GetEntitiesCollectionCount(entityIndex uint64) (uint64, error) {
 	return c.oracle.GetEntityCollectionCount(entityIndex)
 }
@@ -6,6 +6,8 @@ package genesis
 import "errors"
 
 var (
-	ErrInvalidHRP    = errors.New("invalid HRP")
-	ErrInvalidTarget = errors.New("invalid target")
+	ErrInvalidHRP           = errors.New("invalid HRP")
+	ErrInvalidTarget        = errors.New("invalid target")
+	ErrTooManyFeeders       = errors.New("too many feeders")
+	ErrInvalidSlashingRatio = errors.New("invalid slashing ratio")
 )
@@ -8,13 +8,16 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	smath "github.com/ava-labs/avalanchego/utils/math"
 
 	"github.com/ava-labs/hypersdk/chain"
 	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/ava-labs/hypersdk/vm"
 	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
 	"github.com/bianyuanop/oraclevm/storage"
 	"github.com/bianyuanop/oraclevm/utils"
 )
@@ -26,6 +29,17 @@ type CustomAllocation struct {
 	Balance uint64 `json:"balance"`
 }
 
+type EntityRegistration struct {
+	Name       string `json:"name"`
+	Type       uint64 `json:"type"`
+	Aggregator uint64 `json:"aggregator"`
+	// aggregation params, e.g. outlier filter of stock aggregators
+	Params json.RawMessage `json:"params"`
+
+	// bech32 addresses authorized to upload entities
+	Feeders []string `json:"feeders"`
+}
+
 type Genesis struct {
 	// Address prefix
 	HRP string `json:"hrp"`
@@ -47,8 +61,27 @@ type Genesis struct {
 	WarpBaseUnits      uint64 `json:"warpBaseUnits"`
 	WarpUnitsPerSigner uint64 `json:"warpUnitsPerSigner"`
 
+	// Staking Parameters
+	MinFeederStake uint64 `json:"minFeederStake"` // stake allowing uploads to any collection, 0 disables
+	SlashingBand   uint64 `json:"slashingBand"`   // bps from aggregate before slashing, 0 disables
+	SlashingRatio  uint64 `json:"slashingRatio"`  // bps of stake slashed per deviating round
+
+	// Reward Parameters
+	RewardPool  uint64 `json:"rewardPool"`  // initial reward pool, slashed stake is added to it
+	RoundReward uint64 `json:"roundReward"` // paid per aggregation round to accepted publishers
+
+	// Query Parameters
+	QueryChains []ids.ID `json:"queryChains"` // chains allowed to query by warp messages
+
 	// Allocations
 	CustomAllocation []*CustomAllocation `json:"customAllocation"`
+
+	// Admin registering entity collections and managing their feeders,
+	// bech32 address
+	Admin string `json:"admin"`
+
+	// Entity collections, indexed by their position
+	Entities []*EntityRegistration `json:"entities"`
 }
 
 func Default() *Genesis {
@@ -84,9 +117,22 @@ func New(b []byte, _ []byte /* upgradeBytes */) (*Genesis, error) {
 	if g.WindowTargetUnits == 0 {
 		return nil, ErrInvalidTarget
 	}
+	if g.SlashingRatio > consts.BasisPoints {
+		return nil, ErrInvalidSlashingRatio
+	}
 	return g, nil
 }
 
+// QueryChainAllowed returns if [chainID] is allowed to query by warp messages
+func (g *Genesis) QueryChainAllowed(chainID ids.ID) bool {
+	for _, allowed := range g.QueryChains {
+		if allowed == chainID {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, db chain.Database) error {
 	ctx, span := tracer.Start(ctx, "Genesis.Load")
 	defer span.End()
@@ -109,5 +155,64 @@ func (g *Genesis) Load(ctx context.Context, tracer trace.Tracer, db chain.Databa
 			return fmt.Errorf("%w: addr=%s, bal=%d", err, alloc.Address, alloc.Balance)
 		}
 	}
-	return nil
+
+	if err := storage.SetRewardPool(ctx, db, g.RewardPool); err != nil {
+		return fmt.Errorf("%w: pool=%d", err, g.RewardPool)
+	}
+
+	if len(g.Admin) > 0 {
+		admin, err := utils.ParseAddress(g.Admin)
+		if err != nil {
+			return err
+		}
+		if err := storage.SetAdmin(ctx, db, admin); err != nil {
+			return fmt.Errorf("%w: admin=%s", err, g.Admin)
+		}
+	}
+
+	metas := g.EntityMetas()
+	for i, meta := range metas {
+		if err := meta.Verify(); err != nil {
+			return fmt.Errorf("%w: entity=%s", err, meta.EntityName)
+		}
+		if err := storage.StoreEntityMeta(ctx, db, meta); err != nil {
+			return fmt.Errorf("%w: entity=%s", err, meta.EntityName)
+		}
+
+		feeders := g.Entities[i].Feeders
+		if len(feeders) == 0 {
+			continue
+		}
+		if len(feeders) > consts.EntityMaxFeeders {
+			return fmt.Errorf("%w: entity=%s", ErrTooManyFeeders, meta.EntityName)
+		}
+		pks := make([]crypto.PublicKey, 0, len(feeders))
+		for _, feeder := range feeders {
+			pk, err := utils.ParseAddress(feeder)
+			if err != nil {
+				return err
+			}
+			pks = append(pks, pk)
+		}
+		if err := storage.StoreEntityFeeders(ctx, db, meta.EntityID, pks); err != nil {
+			return fmt.Errorf("%w: entity=%s", err, meta.EntityName)
+		}
+	}
+	return storage.SetEntityCounter(ctx, db, uint64(len(metas)))
+}
+
+// EntityMetas returns entity collections registered at genesis
+func (g *Genesis) EntityMetas() []*oracle.EntityCollectionMeta {
+	metas := make([]*oracle.EntityCollectionMeta, len(g.Entities))
+	for i, e := range g.Entities {
+		metas[i] = &oracle.EntityCollectionMeta{
+			EntityName: e.Name,
+			EntityID:   uint64(i),
+			EntityType: e.Type,
+			Aggregator: e.Aggregator,
+			Params:     e.Params,
+		}
+	}
+
+	return metas
 }
@@ -6,6 +6,8 @@ package genesis
 import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/chain"
+
+	"github.com/bianyuanop/oraclevm/consts"
 )
 
 var _ chain.Rules = (*Rules)(nil)
@@ -22,8 +24,13 @@ func (g *Genesis) Rules(_ int64, networkID uint32, chainID ids.ID) *Rules {
 	return &Rules{g, networkID, chainID}
 }
 
-func (*Rules) GetWarpConfig(ids.ID) (bool, uint64, uint64) {
-	return false, 0, 0
+// GetWarpConfig accepts warp messages of chains allowed to query, signed by
+// 80% of the source subnet stake
+func (r *Rules) GetWarpConfig(sourceChainID ids.ID) (bool, uint64, uint64) {
+	if !r.g.QueryChainAllowed(sourceChainID) {
+		return false, 0, 0
+	}
+	return true, 4, 5
 }
 
 func (r *Rules) NetworkID() uint32 {
@@ -70,6 +77,19 @@ func (r *Rules) GetWindowTargetUnits() uint64 {
 	return r.g.WindowTargetUnits
 }
 
-func (*Rules) FetchCustom(string) (any, bool) {
-	return nil, false
+func (r *Rules) FetchCustom(key string) (any, bool) {
+	switch key {
+	case consts.MinFeederStakeKey:
+		return r.g.MinFeederStake, true
+	case consts.SlashingBandKey:
+		return r.g.SlashingBand, true
+	case consts.SlashingRatioKey:
+		return r.g.SlashingRatio, true
+	case consts.RoundRewardKey:
+		return r.g.RoundReward, true
+	case consts.QueryChainsKey:
+		return r.g.QueryChains, true
+	default:
+		return nil, false
+	}
 }
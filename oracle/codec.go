@@ -0,0 +1,71 @@
+package oracle
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// EntityCodecVersion prefixes binary encodings of entities, payloads encoded
+// by previous versions as JSON objects are only decoded by [MigrateEntity]
+// so that records persisted before binary encodings keep working
+const EntityCodecVersion = 1
+
+// IsLegacyPayload reports whether [payload] is a JSON entity encoded before
+// versioned binary encodings
+func IsLegacyPayload(payload []byte) bool {
+	trimmed := bytes.TrimLeft(payload, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// EncodeJSONEntity encodes the JSON object [data] of an entity of [_type] in
+// its binary encoding, used by clients composing uploads
+func EncodeJSONEntity(_type uint64, data []byte) ([]byte, error) {
+	if !IsLegacyPayload(data) {
+		return nil, ErrMarshalEntityFailed
+	}
+	e, err := MigrateEntity(_type, crypto.EmptyPublicKey, 0, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Marshal()
+}
+
+// encodeEntity packs an entity of at most [size] bytes after the codec
+// version, [pack] writes the fields of the entity
+func encodeEntity(size int, pack func(p *codec.Packer)) ([]byte, error) {
+	p := codec.NewWriter(1+size, hconsts.MaxInt)
+	p.PackByte(EntityCodecVersion)
+	pack(p)
+
+	return p.Bytes(), p.Err()
+}
+
+// decodeEntity unpacks [payload] encoded by [encodeEntity] with [unpack],
+// legacy JSON payloads are decoded into [legacy] instead. Decoding is
+// canonical, payloads with trailing bytes are rejected.
+func decodeEntity(payload []byte, legacy any, unpack func(p *codec.Packer)) error {
+	if IsLegacyPayload(payload) {
+		return json.Unmarshal(payload, legacy)
+	}
+
+	p := codec.NewReader(payload, consts.PayloadMaxLen)
+	if version := p.UnpackByte(); p.Err() == nil && version != EntityCodecVersion {
+		return ErrUnknownCodecVersion
+	}
+	unpack(p)
+	if err := p.Err(); err != nil {
+		return err
+	}
+	if !p.Empty() {
+		return ErrTrailingBytes
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package oracle_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestEntityCodec(t *testing.T) {
+	entities := map[uint64]oracle.Entity{
+		oracle.StockID:       oracle.NewStock("AMD", 1000, crypto.EmptyPublicKey, 0),
+		oracle.SportID:       oracle.NewSport("final-1", "Lions", "Tigers", 2, 1, oracle.SportFinished, true, crypto.EmptyPublicKey, 0),
+		oracle.NumericFeedID: oracle.NewNumericFeed(10900, 4, "USD", 10, crypto.EmptyPublicKey, 0),
+	}
+	for _type, e := range entities {
+		payload := marshal(t, e)
+		if payload[0] != oracle.EntityCodecVersion || oracle.IsLegacyPayload(payload) {
+			t.Errorf("payload of type %d is not versioned: %x", _type, payload)
+		}
+
+		restored, err := oracle.UnmarshalEntity(_type, payload)
+		if err != nil {
+			t.Fatalf("decoding type %d failed: %+v", _type, err)
+		}
+		if !bytes.Equal(marshal(t, restored), payload) {
+			t.Errorf("encoding of type %d is not canonical", _type)
+		}
+
+		if _, err := oracle.UnmarshalEntity(_type, append(payload, 0)); err == nil {
+			t.Errorf("trailing bytes of type %d are accepted", _type)
+		}
+		unknown := append([]byte{oracle.EntityCodecVersion + 1}, payload[1:]...)
+		if _, err := oracle.UnmarshalEntity(_type, unknown); err == nil {
+			t.Errorf("unknown version of type %d is accepted", _type)
+		}
+	}
+}
+
+func TestEntityCodecErrors(t *testing.T) {
+	oversized := oracle.NewStock(strings.Repeat("A", math.MaxUint16+1), 1000, crypto.EmptyPublicKey, 0)
+	if _, err := oversized.Marshal(); err == nil {
+		t.Error("oversized entity is encoded")
+	}
+	if _, err := oracle.NewEntityWithMeta(oracle.StockID, 0, oversized).Marshal(); err == nil {
+		t.Error("entity with meta of oversized entity is encoded")
+	}
+}
+
+func TestLegacyEntityCodec(t *testing.T) {
+	legacy := []byte(`{ "ticker": "AMD", "price": 1000 }`)
+	if !oracle.IsLegacyPayload(legacy) {
+		t.Fatal("JSON payload is not legacy")
+	}
+
+	if _, err := oracle.UnmarshalEntity(oracle.StockID, legacy); err != oracle.ErrLegacyPayload {
+		t.Errorf("expected legacy upload to be rejected, got %+v", err)
+	}
+	if _, err := oracle.RestoreEntity(oracle.StockID, crypto.EmptyPublicKey, 10, legacy); err != oracle.ErrLegacyPayload {
+		t.Errorf("expected legacy record to be rejected, got %+v", err)
+	}
+
+	e, err := oracle.MigrateEntity(oracle.StockID, crypto.EmptyPublicKey, 10, legacy)
+	if err != nil {
+		t.Fatalf("decoding legacy payload failed: %+v", err)
+	}
+	if !bytes.Equal(marshal(t, e), marshal(t, oracle.NewStock("AMD", 1000, crypto.EmptyPublicKey, 10))) {
+		t.Errorf("legacy payload restored to %s", oracle.Render(e))
+	}
+
+	ewm, err := oracle.UnmarshalEntityWithMeta([]byte(`{"type":0,"id":1,"entity":{"ticker":"AMD","price":1000},"stale":true}`))
+	if err != nil || ewm.ID != 1 || !ewm.Stale || ewm.Entity.(*oracle.Stock).Price != 1000 {
+		t.Errorf("unexpected legacy entity with meta %+v, %+v", ewm, err)
+	}
+}
+
+func TestEntityWithMetaCodec(t *testing.T) {
+	ewm := oracle.NewEntityWithMeta(oracle.StockID, 3, oracle.NewStock("AMD", 1000, crypto.EmptyPublicKey, 0))
+	ewm.Rejected = []*oracle.RejectedEntity{
+		oracle.NewRejectedEntity(crypto.EmptyPublicKey, oracle.NewStock("AMD", 5000, crypto.EmptyPublicKey, 0)),
+	}
+
+	payload, err := ewm.Marshal()
+	if err != nil {
+		t.Fatalf("encoding failed: %+v", err)
+	}
+	restored, err := oracle.UnmarshalEntityWithMeta(payload)
+	if err != nil {
+		t.Fatalf("decoding failed: %+v", err)
+	}
+	if reencoded, err := restored.Marshal(); err != nil || !bytes.Equal(reencoded, payload) {
+		t.Error("encoding is not canonical")
+	}
+	if restored.ID != 3 || restored.Stale || len(restored.Rejected) != 1 ||
+		restored.Rejected[0].Entity.(*oracle.Stock).Price != 5000 || restored.Entity.(*oracle.Stock).Price != 1000 {
+		t.Errorf("unexpected entity with meta %+v", restored)
+	}
+
+	stale := oracle.NewEntityWithMeta(oracle.StockID, 3, nil)
+	stale.Stale = true
+	payload, err = stale.Marshal()
+	if err != nil {
+		t.Fatalf("encoding failed: %+v", err)
+	}
+	restored, err = oracle.UnmarshalEntityWithMeta(payload)
+	if err != nil || !restored.Stale || restored.Entity != nil {
+		t.Errorf("unexpected stale entity with meta %+v, %+v", restored, err)
+	}
+
+	if _, err := oracle.UnmarshalEntityWithMeta(append(payload, 0)); err != oracle.ErrTrailingBytes {
+		t.Errorf("expected trailing bytes, got %+v", err)
+	}
+}
+
+// marshal returns the encoding of [e], failing [t] on errors
+func marshal(t *testing.T, e oracle.Entity) []byte {
+	t.Helper()
+	payload, err := e.Marshal()
+	if err != nil {
+		t.Fatalf("encoding failed: %+v", err)
+	}
+	return payload
+}
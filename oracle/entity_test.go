@@ -17,7 +17,10 @@ func TestEntityMetaMarshal(t *testing.T) {
 		Entity: entity,
 	}
 
-	marshalled := entityWithMeta.Marshal()
+	marshalled, err := entityWithMeta.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	restored, err := oracle.UnmarshalEntityWithMeta(marshalled)
 
@@ -35,10 +38,10 @@ func TestEntityMetaMarshal(t *testing.T) {
 }
 
 func TestUnmarshalEntity(t *testing.T) {
-	payload := `{ "ticker": "Apple", "price": 1999 }`
+	payload := marshal(t, oracle.NewStock("Apple", 1999, crypto.EmptyPublicKey, 0))
 	sType := oracle.StockID
 
-	_, err := oracle.UnmarshalEntity(uint64(sType), []byte(payload))
+	_, err := oracle.UnmarshalEntity(uint64(sType), payload)
 	if err != nil {
 		t.Errorf("Unmarshal entity failed: %+v\n", err)
 	}
@@ -47,10 +50,10 @@ func TestUnmarshalEntity(t *testing.T) {
 }
 
 func TestMarshalEntityMeta(t *testing.T) {
-	payload := `{ "ticker": "Apple", "price": 1999 }`
+	payload := marshal(t, oracle.NewStock("Apple", 1999, crypto.EmptyPublicKey, 0))
 	sType := oracle.StockID
 
-	entity, err := oracle.UnmarshalEntity(uint64(sType), []byte(payload))
+	entity, err := oracle.UnmarshalEntity(uint64(sType), payload)
 	if err != nil {
 		t.Errorf("Unmarshal entity failed: %+v\n", err)
 	}
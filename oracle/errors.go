@@ -10,4 +10,30 @@ var (
 	ErrNotSupportedEntity         = errors.New("Such entity is not supported")
 	ErrMarshalEntityFailed        = errors.New("Marshal entity failed")
 	ErrUnexpectedEntityType       = errors.New("Unexpected entity type")
+	ErrNotSupportedAggregator     = errors.New("Such aggregator is not supported")
+	ErrInvalidEntityName          = errors.New("Invalid entity name")
+	ErrEntityParamsTooLarge       = errors.New("Entity params too large")
+	ErrUnexpectedEntityIndex      = errors.New("Unexpected entity index")
+	ErrWeightsMismatch            = errors.New("Weights mismatch entities")
+	ErrInvalidParams              = errors.New("Invalid entity collection params")
+	ErrInvalidWindow              = errors.New("Invalid time window")
+	ErrNoObservations             = errors.New("No observations within time window")
+	ErrQuorumNotReached           = errors.New("Quorum of publishers not reached")
+	ErrInvalidSportStatus         = errors.New("Invalid sport event status")
+	ErrInvalidEntityType          = errors.New("Invalid entity type")
+	ErrDuplicateEntityType        = errors.New("Entity type already registered")
+	ErrInvalidDecimals            = errors.New("Invalid decimals")
+	ErrUnitMismatch               = errors.New("Unit mismatches entity collection")
+	ErrDecimalsOverflow           = errors.New("Value overflows decimals of entity collection")
+	ErrUnknownCodecVersion        = errors.New("Unknown entity codec version")
+	ErrTrailingBytes              = errors.New("Trailing bytes after entity")
+	ErrLegacyPayload              = errors.New("Legacy JSON entity payload")
+	ErrInvalidRejectedEntities    = errors.New("Invalid number of rejected entities")
+	ErrRequiredField              = errors.New("Required field is missing")
+	ErrFieldTooLong               = errors.New("Field is too long")
+	ErrNotPositive                = errors.New("Value must be positive")
+	ErrNameMismatch               = errors.New("Name mismatches entity collection")
+	ErrUnfinishedEvent            = errors.New("Outcome of unfinished event can't be final")
+	ErrNoConsensus                = errors.New("No outcome reported by majority")
+	ErrNoAcceptedEntities         = errors.New("Every entity rejected as outlier")
 )
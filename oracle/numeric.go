@@ -0,0 +1,437 @@
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+func init() {
+	MustRegisterEntityType(&EntityType{
+		ID:   NumericFeedID,
+		Name: "numeric",
+		Decode: func(payload []byte, publisher crypto.PublicKey, tick int64) (Entity, error) {
+			n, err := UnmarshalNumericFeed(payload)
+			if err != nil {
+				return nil, err
+			}
+			n.publisher = publisher
+			n.tick = tick
+
+			return n, nil
+		},
+		Validate: func(e Entity) error {
+			n, ok := e.(*NumericFeed)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+
+			return n.Verify()
+		},
+		Accepts: func(e Entity, meta *EntityCollectionMeta) error {
+			n, ok := e.(*NumericFeed)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+			format, err := ParseFeedFormat(meta.Params)
+			if err != nil {
+				return err
+			}
+
+			return format.Accepts(n)
+		},
+		VerifyParams: func(params []byte) error {
+			_, err := ParseFeedFormat(params)
+			return err
+		},
+		Aggregators: map[uint64]AggregatorConstructor{
+			MeanAggregatorID:   func(name string, params []byte) EntityAggregator { return NewNumericFeedAggregator(name, params) },
+			MedianAggregatorID: func(name string, params []byte) EntityAggregator { return NewNumericFeedMedianAggregator(name, params) },
+		},
+		Deviation: func(e Entity, result Entity) (uint64, error) {
+			n, ok := e.(*NumericFeed)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+			ref, ok := result.(*NumericFeed)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+
+			return n.Deviation(ref), nil
+		},
+		Measure: func(e Entity) (uint64, bool) {
+			n, ok := e.(*NumericFeed)
+			if !ok {
+				return 0, false
+			}
+			return n.Value, true
+		},
+		WithValue: func(latest Entity, value uint64, tick int64) (Entity, error) {
+			n, ok := latest.(*NumericFeed)
+			if !ok {
+				return nil, ErrUnexpectedEntityType
+			}
+
+			return NewNumericFeed(value, n.Decimals, n.Unit, n.Confidence, n.publisher, tick), nil
+		},
+	})
+}
+
+// NumericFeed is a fixed-point value, e.g. an exchange rate or a commodity
+// price, [Value] is scaled by 10^[Decimals]
+type NumericFeed struct {
+	Value    uint64 `json:"value"`
+	Decimals uint8  `json:"decimals"`
+	// unit or currency of the value, e.g. `USD`
+	Unit string `json:"unit"`
+	// half width of the confidence interval around [Value] in the same
+	// scale, 0 if not reported
+	Confidence uint64 `json:"confidence,omitempty"`
+
+	publisher crypto.PublicKey
+	tick      int64
+}
+
+func NewNumericFeed(
+	value uint64,
+	decimals uint8,
+	unit string,
+	confidence uint64,
+	publisher crypto.PublicKey,
+	tick int64,
+) (n *NumericFeed) {
+	n = new(NumericFeed)
+	n.Value = value
+	n.Decimals = decimals
+	n.Unit = unit
+	n.Confidence = confidence
+	n.publisher = publisher
+	n.tick = tick
+
+	return n
+}
+
+func (n *NumericFeed) Publisher() string {
+	return string(n.publisher[:])
+}
+
+func (n *NumericFeed) Tick() int64 {
+	return n.tick
+}
+
+// Verify checks the bounds of the decimals and unit of [n]
+func (n *NumericFeed) Verify() error {
+	if n.Decimals > consts.NumericFeedMaxDecimals {
+		return NewFieldError("decimals", ErrInvalidDecimals)
+	}
+	if len(n.Unit) == 0 {
+		return NewFieldError("unit", ErrRequiredField)
+	}
+	if len(n.Unit) > consts.NumericFeedUnitMaxLen {
+		return NewFieldError("unit", ErrFieldTooLong)
+	}
+
+	return nil
+}
+
+// Deviation returns the distance between the value of [n] and [ref] in basis
+// points of the [ref] value, [n] is scaled to the decimals of [ref] first
+func (n *NumericFeed) Deviation(ref *NumericFeed) uint64 {
+	value, ok := scale(n.Value, n.Decimals, ref.Decimals)
+	if !ok {
+		return math.MaxUint64
+	}
+
+	return deviation(value, ref.Value)
+}
+
+// Scaled renders the value of [n] as a decimal number, e.g. `1.2345`
+func (n *NumericFeed) Scaled() string {
+	return formatScaled(n.Value, n.Decimals)
+}
+
+func (n *NumericFeed) String() string {
+	if n.Confidence == 0 {
+		return fmt.Sprintf("%s %s", n.Scaled(), n.Unit)
+	}
+	return fmt.Sprintf("%s ± %s %s", n.Scaled(), formatScaled(n.Confidence, n.Decimals), n.Unit)
+}
+
+func (n *NumericFeed) Marshal() ([]byte, error) {
+	return encodeEntity(hconsts.Uint64Len*2+1+codec.StringLen(n.Unit), func(p *codec.Packer) {
+		p.PackUint64(n.Value)
+		p.PackByte(n.Decimals)
+		p.PackString(n.Unit)
+		p.PackUint64(n.Confidence)
+	})
+}
+
+func UnmarshalNumericFeed(payload []byte) (*NumericFeed, error) {
+	var n NumericFeed
+	err := decodeEntity(payload, &n, func(p *codec.Packer) {
+		n.Value = p.UnpackUint64(false)
+		n.Decimals = p.UnpackByte()
+		n.Unit = p.UnpackString(false)
+		n.Confidence = p.UnpackUint64(false)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// FeedFormat is the scale and unit of aggregation results of a numeric feed
+// collection, it is configured by the params of the collection. Submissions
+// of other decimals are scaled to the format before aggregation.
+type FeedFormat struct {
+	Decimals uint8  `json:"decimals"`
+	Unit     string `json:"unit"`
+}
+
+// ParseFeedFormat decodes collection params, numeric feed collections must
+// declare their unit
+func ParseFeedFormat(params []byte) (*FeedFormat, error) {
+	f := new(FeedFormat)
+	if len(params) == 0 {
+		return nil, ErrInvalidParams
+	}
+	if err := json.Unmarshal(params, f); err != nil {
+		return nil, ErrInvalidParams
+	}
+	if f.Decimals > consts.NumericFeedMaxDecimals {
+		return nil, ErrInvalidParams
+	}
+	if len(f.Unit) == 0 || len(f.Unit) > consts.NumericFeedUnitMaxLen {
+		return nil, ErrInvalidParams
+	}
+
+	return f, nil
+}
+
+// Accepts checks that [n] is in the unit of [f] and can be scaled to its
+// decimals
+func (f *FeedFormat) Accepts(n *NumericFeed) error {
+	if n.Unit != f.Unit {
+		return NewFieldError("unit", ErrUnitMismatch)
+	}
+	if _, ok := f.Normalize(n.Value, n.Decimals); !ok {
+		return NewFieldError("value", ErrDecimalsOverflow)
+	}
+	if _, ok := f.Normalize(n.Confidence, n.Decimals); !ok {
+		return NewFieldError("confidence", ErrDecimalsOverflow)
+	}
+
+	return nil
+}
+
+// Normalize scales [value] of [decimals] to the decimals of [f], extra
+// decimals are truncated, false is returned on overflows
+func (f *FeedFormat) Normalize(value uint64, decimals uint8) (uint64, bool) {
+	return scale(value, decimals, f.Decimals)
+}
+
+func scale(value uint64, from uint8, to uint8) (uint64, bool) {
+	if from >= to {
+		return value / pow10(from-to), true
+	}
+	p := pow10(to - from)
+	if value > math.MaxUint64/p {
+		return 0, false
+	}
+	return value * p, true
+}
+
+// pow10 returns 10^[n], [n] is bounded by [consts.NumericFeedMaxDecimals]
+func pow10(n uint8) uint64 {
+	res := uint64(1)
+	for i := uint8(0); i < n; i++ {
+		res *= 10
+	}
+	return res
+}
+
+func formatScaled(value uint64, decimals uint8) string {
+	digits := strconv.FormatUint(value, 10)
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(decimals)
+	return digits[:point] + "." + digits[point:]
+}
+
+// parseFormat returns the format of a collection configured by [params],
+// aggregators of unverified params use the zero format
+func parseFormat(params []byte) *FeedFormat {
+	format, err := ParseFeedFormat(params)
+	if err != nil {
+		return new(FeedFormat)
+	}
+	return format
+}
+
+// NumericFeedAggregator computes the mean of merged values scaled to the
+// format of the collection, entities that can't be scaled are ignored
+type NumericFeedAggregator struct {
+	format     *FeedFormat
+	sum        *big.Int
+	confidence *big.Int
+	count      uint64
+}
+
+func NewNumericFeedAggregator(name string, params []byte) *NumericFeedAggregator {
+	res := new(NumericFeedAggregator)
+	res.format = parseFormat(params)
+	res.sum = new(big.Int)
+	res.confidence = new(big.Int)
+
+	return res
+}
+
+func (nfa *NumericFeedAggregator) Result(t int64) (Entity, error) {
+	if nfa.count == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	count := new(big.Int).SetUint64(nfa.count)
+	value := new(big.Int).Quo(nfa.sum, count).Uint64()
+	confidence := new(big.Int).Quo(nfa.confidence, count).Uint64()
+
+	return NewNumericFeed(value, nfa.format.Decimals, nfa.format.Unit, confidence, crypto.EmptyPublicKey, t), nil
+}
+
+func (nfa *NumericFeedAggregator) Measure(e Entity) (uint64, bool) {
+	return nfa.format.measure(e)
+}
+
+func (nfa *NumericFeedAggregator) MergeOne(e Entity) {
+	value, confidence, ok := nfa.format.normalizeEntity(e)
+	if !ok {
+		return
+	}
+
+	nfa.sum.Add(nfa.sum, new(big.Int).SetUint64(value))
+	nfa.confidence.Add(nfa.confidence, new(big.Int).SetUint64(confidence))
+	nfa.count++
+}
+
+func (nfa *NumericFeedAggregator) RemoveOne(e Entity) {
+	value, confidence, ok := nfa.format.normalizeEntity(e)
+	if !ok {
+		return
+	}
+
+	nfa.sum.Sub(nfa.sum, new(big.Int).SetUint64(value))
+	nfa.confidence.Sub(nfa.confidence, new(big.Int).SetUint64(confidence))
+	nfa.count--
+}
+
+// NumericFeedMedianAggregator keeps merged values and confidences scaled to
+// the format of the collection sorted, the result carries the median of both
+type NumericFeedMedianAggregator struct {
+	format      *FeedFormat
+	values      []uint64
+	confidences []uint64
+}
+
+func NewNumericFeedMedianAggregator(name string, params []byte) *NumericFeedMedianAggregator {
+	res := new(NumericFeedMedianAggregator)
+	res.format = parseFormat(params)
+	res.values = make([]uint64, 0)
+	res.confidences = make([]uint64, 0)
+
+	return res
+}
+
+func (nfma *NumericFeedMedianAggregator) Result(t int64) (Entity, error) {
+	if len(nfma.values) == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	return NewNumericFeed(
+		median(nfma.values),
+		nfma.format.Decimals,
+		nfma.format.Unit,
+		median(nfma.confidences),
+		crypto.EmptyPublicKey,
+		t,
+	), nil
+}
+
+func (nfma *NumericFeedMedianAggregator) Measure(e Entity) (uint64, bool) {
+	return nfma.format.measure(e)
+}
+
+func (nfma *NumericFeedMedianAggregator) MergeOne(e Entity) {
+	value, confidence, ok := nfma.format.normalizeEntity(e)
+	if !ok {
+		return
+	}
+
+	nfma.values = insertSorted(nfma.values, value)
+	nfma.confidences = insertSorted(nfma.confidences, confidence)
+}
+
+func (nfma *NumericFeedMedianAggregator) RemoveOne(e Entity) {
+	value, confidence, ok := nfma.format.normalizeEntity(e)
+	if !ok {
+		return
+	}
+
+	nfma.values = removeSorted(nfma.values, value)
+	nfma.confidences = removeSorted(nfma.confidences, confidence)
+}
+
+// measure returns the value of [e] compared by [OutlierFilter] in the scale
+// of [f]
+func (f *FeedFormat) measure(e Entity) (uint64, bool) {
+	value, _, ok := f.normalizeEntity(e)
+	return value, ok
+}
+
+func (f *FeedFormat) normalizeEntity(e Entity) (uint64, uint64, bool) {
+	n, ok := e.(*NumericFeed)
+	if !ok {
+		return 0, 0, false
+	}
+	value, ok := f.Normalize(n.Value, n.Decimals)
+	if !ok {
+		return 0, 0, false
+	}
+	confidence, ok := f.Normalize(n.Confidence, n.Decimals)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return value, confidence, true
+}
+
+func insertSorted(values []uint64, v uint64) []uint64 {
+	i := sort.Search(len(values), func(i int) bool { return values[i] >= v })
+	values = append(values, 0)
+	copy(values[i+1:], values[i:])
+	values[i] = v
+	return values
+}
+
+func removeSorted(values []uint64, v uint64) []uint64 {
+	i := sort.Search(len(values), func(i int) bool { return values[i] >= v })
+	if i == len(values) || values[i] != v {
+		return values
+	}
+	return append(values[:i], values[i+1:]...)
+}
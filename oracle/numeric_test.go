@@ -0,0 +1,93 @@
+package oracle_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestNumericFeedAggregate(t *testing.T) {
+	params := []byte(`{"decimals":4,"unit":"USD"}`)
+	for _, kind := range []uint64{oracle.MeanAggregatorID, oracle.MedianAggregatorID} {
+		collection := oracle.NewEntityCollection(0, 0, oracle.NumericFeedID, kind, "EUR/USD")
+		if err := collection.SetParams(params); err != nil {
+			t.Fatalf("invalid params: %+v", err)
+		}
+
+		// 1.0800, 1.0900 and 1.1000 reported with different decimals
+		collection.MergeMany([]oracle.Entity{
+			oracle.NewNumericFeed(108, 2, "USD", 1, crypto.EmptyPublicKey, 0),
+			oracle.NewNumericFeed(10900, 4, "USD", 100, crypto.EmptyPublicKey, 0),
+			oracle.NewNumericFeed(1100000, 6, "USD", 10000, crypto.EmptyPublicKey, 0),
+		})
+		res, err := collection.Result(10)
+		if err != nil {
+			t.Fatalf("aggregation failed: %+v", err)
+		}
+		feed := res.(*oracle.NumericFeed)
+		if feed.Value != 10900 || feed.Decimals != 4 || feed.Unit != "USD" || feed.Confidence != 100 {
+			t.Errorf("unexpected result of aggregator %d: %+v", kind, feed)
+		}
+		if rendered := oracle.Render(feed); rendered != "1.0900 ± 0.0100 USD" {
+			t.Errorf("unexpected rendering %s", rendered)
+		}
+	}
+}
+
+func TestNumericFeedFormat(t *testing.T) {
+	for _, params := range []string{``, `{"decimals":4}`, `{"decimals":19,"unit":"USD"}`} {
+		if _, err := oracle.ParseFeedFormat([]byte(params)); err != oracle.ErrInvalidParams {
+			t.Errorf("expected %s to be invalid, got %+v", params, err)
+		}
+	}
+
+	meta := &oracle.EntityCollectionMeta{
+		EntityName: "Gold",
+		EntityType: oracle.NumericFeedID,
+		Aggregator: oracle.MedianAggregatorID,
+		Params:     []byte(`{"decimals":15,"unit":"XAU"}`),
+	}
+	if err := meta.Verify(); err != nil {
+		t.Fatalf("unexpected invalid meta: %+v", err)
+	}
+	if err := meta.Accepts(oracle.NewNumericFeed(2000, 0, "XAU", 0, crypto.EmptyPublicKey, 0)); err != nil {
+		t.Errorf("unexpected rejection: %+v", err)
+	}
+	if err := meta.Accepts(oracle.NewNumericFeed(2000, 0, "USD", 0, crypto.EmptyPublicKey, 0)); !errors.Is(err, oracle.ErrUnitMismatch) {
+		t.Errorf("expected unit mismatch, got %+v", err)
+	}
+	if err := meta.Accepts(oracle.NewNumericFeed(2000, 18, "XAU", 0, crypto.EmptyPublicKey, 0)); err != nil {
+		t.Errorf("unexpected rejection: %+v", err)
+	}
+	if err := meta.Accepts(oracle.NewNumericFeed(20_000_000_000, 0, "XAU", 0, crypto.EmptyPublicKey, 0)); !errors.Is(err, oracle.ErrDecimalsOverflow) {
+		t.Errorf("expected overflow, got %+v", err)
+	}
+
+	meta.Params = nil
+	if err := meta.Verify(); err != oracle.ErrInvalidParams {
+		t.Errorf("expected missing unit to be invalid, got %+v", err)
+	}
+
+	if _, err := oracle.UnmarshalEntity(oracle.NumericFeedID, marshal(t, oracle.NewNumericFeed(1, 19, "USD", 0, crypto.EmptyPublicKey, 0))); !errors.Is(err, oracle.ErrInvalidDecimals) {
+		t.Errorf("expected invalid decimals, got %+v", err)
+	}
+	if _, err := oracle.UnmarshalEntity(oracle.NumericFeedID, marshal(t, oracle.NewNumericFeed(1, 0, "", 0, crypto.EmptyPublicKey, 0))); !errors.Is(err, oracle.ErrRequiredField) {
+		t.Errorf("expected invalid unit, got %+v", err)
+	}
+}
+
+func TestNumericFeedDeviation(t *testing.T) {
+	ref := oracle.NewNumericFeed(10000, 4, "USD", 0, crypto.EmptyPublicKey, 0)
+	// 1.1 deviates 10% from 1.0000
+	d, err := oracle.Deviation(oracle.NumericFeedID, oracle.NewNumericFeed(11, 1, "USD", 0, crypto.EmptyPublicKey, 0), ref)
+	if err != nil || d != 1_000 {
+		t.Errorf("unexpected deviation %d, %+v", d, err)
+	}
+
+	twap, err := oracle.TimeWeighted(oracle.NumericFeedID, ref, []oracle.Observation{{Tick: 0, Value: 10000}, {Tick: 5, Value: 12000}}, 10, 10)
+	if err != nil || oracle.Render(twap) != "1.1000 USD" {
+		t.Errorf("unexpected twap %+v, %+v", twap, err)
+	}
+}
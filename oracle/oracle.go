@@ -3,28 +3,38 @@ package oracle
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
-	"sort"
 	"sync"
 
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/utils"
 )
 
 const (
-	StockID = 0
-	SportID = iota
+	StockID       = 0
+	SportID       = iota
+	NumericFeedID = iota
+)
+
+// aggregation rules selectable per entity collection
+const (
+	MeanAggregatorID           = 0
+	MedianAggregatorID         = 1
+	WeightedMeanAggregatorID   = 2
+	WeightedMedianAggregatorID = 3
+	// majority vote on the reported outcome
+	ConsensusAggregatorID = 4
 )
 
 func EntityIDToTypeString(id uint64) (res string) {
-	switch id {
-	case 0:
-		// should return `*oracle.Stock`
-		res = reflect.TypeOf(&Stock{}).String()
-	default:
-		res = "Unknown"
+	et, ok := LookupEntityType(id)
+	if !ok {
+		return "Unknown"
 	}
 
-	return
+	return et.Name
 }
 
 func EntityName(id uint64, _type uint64) (res string) {
@@ -37,6 +47,24 @@ type EntityWithMeta struct {
 	Type   uint64 `json:"type"`
 	ID     uint64 `json:"id"`
 	Entity Entity `json:"entity"`
+
+	// entities discarded as outliers by the aggregation
+	Rejected []*RejectedEntity `json:"rejected,omitempty"`
+	// set when the round closed without reaching quorum, [Entity] is the
+	// previous result retained, nil if the collection was never aggregated
+	Stale bool `json:"stale,omitempty"`
+}
+
+type RejectedEntity struct {
+	Publisher string `json:"publisher"` // bech32 address
+	Entity    Entity `json:"entity"`
+}
+
+func NewRejectedEntity(publisher crypto.PublicKey, e Entity) *RejectedEntity {
+	return &RejectedEntity{
+		Publisher: utils.Address(publisher),
+		Entity:    e,
+	}
 }
 
 func NewEntityWithMeta(_type uint64, id uint64, entity Entity) *EntityWithMeta {
@@ -47,76 +75,280 @@ func NewEntityWithMeta(_type uint64, id uint64, entity Entity) *EntityWithMeta {
 	}
 }
 
-func (ewm *EntityWithMeta) Marshal() []byte {
-	res, _ := json.Marshal(ewm)
+// Marshal encodes [ewm] after the codec version, entities are encoded by
+// their own `Marshal`
+func (ewm *EntityWithMeta) Marshal() ([]byte, error) {
+	p := codec.NewWriter(1+hconsts.Uint64Len*2+hconsts.BoolLen, hconsts.MaxInt)
+	p.PackByte(EntityCodecVersion)
+	p.PackUint64(ewm.Type)
+	p.PackUint64(ewm.ID)
+	p.PackBool(ewm.Stale)
+	if err := packEntity(p, ewm.Entity); err != nil {
+		return nil, err
+	}
+	p.PackInt(len(ewm.Rejected))
+	for _, r := range ewm.Rejected {
+		p.PackString(r.Publisher)
+		if err := packEntity(p, r.Entity); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.Bytes(), p.Err()
+}
 
-	return res
+// packEntity packs the encoding of [e], nil entities are packed empty
+func packEntity(p *codec.Packer, e Entity) error {
+	if e == nil {
+		p.PackBytes(nil)
+		return nil
+	}
+	payload, err := e.Marshal()
+	if err != nil {
+		return err
+	}
+	p.PackBytes(payload)
+	return nil
 }
 
 func UnmarshalEntityWithMeta(payload []byte) (*EntityWithMeta, error) {
+	if IsLegacyPayload(payload) {
+		return unmarshalLegacyEntityWithMeta(payload)
+	}
+
+	res := new(EntityWithMeta)
+	p := codec.NewReader(payload, hconsts.MaxInt)
+	if version := p.UnpackByte(); p.Err() == nil && version != EntityCodecVersion {
+		return nil, ErrUnknownCodecVersion
+	}
+	res.Type = p.UnpackUint64(false)
+	res.ID = p.UnpackUint64(false)
+	res.Stale = p.UnpackBool()
+
+	var raw []byte
+	p.UnpackBytes(consts.PayloadMaxLen, false, &raw)
+	count := p.UnpackInt(false)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	if count > consts.RoundMaxSubmissions {
+		return nil, ErrInvalidRejectedEntities
+	}
+	entity, err := unmarshalKnownEntity(res.Type, raw)
+	if err != nil {
+		return nil, err
+	}
+	res.Entity = entity
+
+	for i := 0; i < count; i++ {
+		publisher := p.UnpackString(true)
+		p.UnpackBytes(consts.PayloadMaxLen, true, &raw)
+		if err := p.Err(); err != nil {
+			return nil, err
+		}
+		entity, err := unmarshalKnownEntity(res.Type, raw)
+		if err != nil {
+			return nil, err
+		}
+		res.Rejected = append(res.Rejected, &RejectedEntity{Publisher: publisher, Entity: entity})
+	}
+	if !p.Empty() {
+		return nil, ErrTrailingBytes
+	}
+
+	return res, nil
+}
+
+// unmarshalLegacyEntityWithMeta decodes [EntityWithMeta] encoded as JSON
+// before versioned binary encodings, e.g. outputs of previous blocks
+func unmarshalLegacyEntityWithMeta(payload []byte) (*EntityWithMeta, error) {
 	var res *EntityWithMeta = &EntityWithMeta{}
 
 	// same with `EntityWIthMeta` except we defer value decoding
 	var data struct {
-		Type   uint64          `json:"type"`
-		ID     uint64          `json:"id"`
-		Entity json.RawMessage `json:"entity"`
+		Type     uint64          `json:"type"`
+		ID       uint64          `json:"id"`
+		Entity   json.RawMessage `json:"entity"`
+		Rejected []struct {
+			Publisher string          `json:"publisher"`
+			Entity    json.RawMessage `json:"entity"`
+		} `json:"rejected"`
+		Stale bool `json:"stale"`
 	}
 
 	if err := json.Unmarshal(payload, &data); err != nil {
 		return nil, err
 	}
 
-	res.ID = data.Type
+	res.Type = data.Type
 	res.ID = data.ID
+	res.Stale = data.Stale
 
-	for _, impl := range entityKnownImplementations {
-		_type := reflect.TypeOf(impl)
-		if _type.String() == EntityIDToTypeString(data.Type) {
-			target := reflect.New(_type)
-			if err := json.Unmarshal(data.Entity, target.Interface()); err != nil {
-				return nil, err
-			}
+	entity, err := unmarshalKnownEntity(data.Type, data.Entity)
+	if err != nil {
+		return nil, err
+	}
+	res.Entity = entity
 
-			res.Entity = target.Elem().Interface().(Entity)
-			break
+	for _, r := range data.Rejected {
+		entity, err := unmarshalKnownEntity(data.Type, r.Entity)
+		if err != nil {
+			return nil, err
 		}
+		res.Rejected = append(res.Rejected, &RejectedEntity{Publisher: r.Publisher, Entity: entity})
 	}
 
 	return res, nil
 }
 
+// unmarshalKnownEntity decodes [raw] with the registered decoder of [_type],
+// nil is returned for unknown types
+func unmarshalKnownEntity(_type uint64, raw []byte) (Entity, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	et, ok := LookupEntityType(_type)
+	if !ok {
+		return nil, nil
+	}
+
+	return et.Decode(raw, crypto.EmptyPublicKey, 0)
+}
+
 type Entity interface {
 	Publisher() string
 	Tick() int64
-	Marshal() []byte
+	Marshal() ([]byte, error)
 }
 
-// to be used for dynamically unmarshal and marshal EntityWithMeta
-var (
-	entityKnownImplementations = []Entity{
-		&Stock{},
+// Render formats [e] for humans, e.g. scaled values of numeric feeds, empty
+// for entities implementing no [fmt.Stringer]
+func Render(e Entity) string {
+	s, ok := e.(fmt.Stringer)
+	if !ok {
+		return ""
 	}
-)
+	return s.String()
+}
 
+// UnmarshalEntity decodes and validates a payload uploaded by publishers,
+// uploads must be encoded in the binary encoding
 func UnmarshalEntity(_type uint64, payload []byte) (Entity, error) {
-	switch int(_type) {
-	case StockID:
-		s, err := UnmarshalStock(payload)
-		if err != nil {
-			return nil, ErrMarshalEntityFailed
+	et, ok := LookupEntityType(_type)
+	if !ok {
+		return nil, ErrNotSupportedEntity
+	}
+	if IsLegacyPayload(payload) {
+		return nil, ErrLegacyPayload
+	}
+
+	e, err := et.Decode(payload, crypto.EmptyPublicKey, 0)
+	if err != nil {
+		return nil, ErrMarshalEntityFailed
+	}
+	if et.Validate != nil {
+		if err := et.Validate(e); err != nil {
+			return nil, err
 		}
+	}
 
-		return Entity(s), nil
-	default:
+	return e, nil
+}
+
+// RestoreEntity decodes a persisted payload together with the publisher and
+// tick it was submitted with
+func RestoreEntity(_type uint64, publisher crypto.PublicKey, tick int64, payload []byte) (Entity, error) {
+	if IsLegacyPayload(payload) {
+		return nil, ErrLegacyPayload
+	}
+
+	return MigrateEntity(_type, publisher, tick, payload)
+}
+
+// MigrateEntity is [RestoreEntity] also decoding records persisted as JSON
+// by previous versions, only used to migrate such records
+func MigrateEntity(_type uint64, publisher crypto.PublicKey, tick int64, payload []byte) (Entity, error) {
+	et, ok := LookupEntityType(_type)
+	if !ok {
 		return nil, ErrNotSupportedEntity
 	}
+
+	e, err := et.Decode(payload, publisher, tick)
+	if err != nil {
+		return nil, ErrMarshalEntityFailed
+	}
+
+	return e, nil
+}
+
+// Aggregate merges [es] with a fresh aggregator of [_type] and [kind], used to
+// compute aggregation results during block execution, the result is stamped
+// with the block timestamp [t]. [params] of the collection configure the
+// aggregator. [weights] of publishers are only used by
+// [WeightedAggregator], nil weights every entity equally. Indices of entities
+// rejected by [filter] are returned along with the result, no result is
+// produced when every entity is rejected.
+func Aggregate(
+	_type uint64,
+	kind uint64,
+	params []byte,
+	filter *OutlierFilter,
+	t int64,
+	es []Entity,
+	weights []uint64,
+) (Entity, []int, error) {
+	if len(es) == 0 {
+		return nil, nil, ErrEmptyEntities
+	}
+	if weights != nil && len(weights) != len(es) {
+		return nil, nil, ErrWeightsMismatch
+	}
+
+	aggregator := AggregatorFactory(_type, kind, "", params)
+	outliers := filter.Outliers(aggregator, nil, es)
+	if len(outliers) == len(es) {
+		return nil, outliers, ErrNoAcceptedEntities
+	}
+	rejected := make(map[int]bool, len(outliers))
+	for _, i := range outliers {
+		rejected[i] = true
+	}
+
+	weighted, ok := aggregator.(WeightedAggregator)
+	for i, e := range es {
+		if rejected[i] {
+			continue
+		}
+		if ok && weights != nil {
+			weighted.MergeWeighted(e, weights[i])
+		} else {
+			aggregator.MergeOne(e)
+		}
+	}
+
+	result, err := aggregator.Result(t)
+	return result, outliers, err
+}
+
+// Deviation returns how far [e] deviates from the aggregation [result] in
+// basis points
+func Deviation(_type uint64, e Entity, result Entity) (uint64, error) {
+	et, ok := LookupEntityType(_type)
+	if !ok || et.Deviation == nil {
+		return 0, ErrNotSupportedEntity
+	}
+
+	return et.Deviation(e, result)
 }
 
 type EntityAggregator interface {
-	Result() (Entity, error)
+	// Result aggregates merged entities, [t] is the block timestamp the result is produced at
+	Result(t int64) (Entity, error)
 	MergeOne(Entity)
 	RemoveOne(Entity)
+	// Measure returns the value of an entity compared to reject outliers,
+	// entities that can't be measured are never rejected
+	Measure(Entity) (uint64, bool)
 }
 
 type DefaultAggregator struct{}
@@ -125,11 +357,22 @@ func NewDefaultAggregator() *DefaultAggregator {
 	return &DefaultAggregator{}
 }
 
-func (da *DefaultAggregator) Result() (Entity, error) {
+func (da *DefaultAggregator) Result(int64) (Entity, error) {
 	return nil, nil
 }
 func (da *DefaultAggregator) MergeOne(Entity)  {}
 func (da *DefaultAggregator) RemoveOne(Entity) {}
+func (da *DefaultAggregator) Measure(Entity) (uint64, bool) {
+	return 0, false
+}
+
+// WeightedAggregator weights entities by their publisher, `MergeOne` and
+// `RemoveOne` use the weight 1
+type WeightedAggregator interface {
+	EntityAggregator
+	MergeWeighted(e Entity, weight uint64)
+	RemoveWeighted(e Entity, weight uint64)
+}
 
 // type Aggregatable interface {
 // 	Aggregate([]Entity) (Entity, error)
@@ -148,24 +391,39 @@ type EntityCollecton struct {
 	// FIFO queue
 	Entities []Entity
 
-	aggregator EntityAggregator
-	_type      uint64
+	aggregator     EntityAggregator
+	aggregatorKind uint64
+	params         []byte
+	quorum         *Quorum
+	_type          uint64
 }
 
-func AggregatorFactory(_type uint64, name string) (aggregator EntityAggregator) {
-	switch _type {
-	case 0:
-		aggregator = NewStockAggregator(name)
-	default:
-		aggregator = NewDefaultAggregator()
+// AggregatorFactory creates aggregator [kind] registered for [_type]
+// configured by [params], the default aggregator is returned for unknown
+// combinations
+func AggregatorFactory(_type uint64, kind uint64, name string, params []byte) EntityAggregator {
+	et, ok := LookupEntityType(_type)
+	if !ok {
+		return NewDefaultAggregator()
+	}
+	constructor, ok := et.Aggregators[kind]
+	if !ok {
+		return NewDefaultAggregator()
 	}
 
-	return
+	return constructor(name, params)
+}
+
+// IsSupportedAggregator reports whether [AggregatorFactory] knows aggregator [kind] for [_type]
+func IsSupportedAggregator(_type uint64, kind uint64) bool {
+	_, ok := AggregatorFactory(_type, kind, "", nil).(*DefaultAggregator)
+	return !ok
 }
 
-func NewEntityCollection(t int64, id uint64, _type uint64, name string) (ec *EntityCollecton) {
+func NewEntityCollection(t int64, id uint64, _type uint64, kind uint64, name string) (ec *EntityCollecton) {
 	ec = new(EntityCollecton)
 	ec._type = _type
+	ec.aggregatorKind = kind
 	ec.EntityID = id
 	ec.MaxTick = t
 	ec.MinTick = t
@@ -174,23 +432,60 @@ func NewEntityCollection(t int64, id uint64, _type uint64, name string) (ec *Ent
 	ec.EntityType = EntityIDToTypeString(_type)
 	ec.Entities = make([]Entity, 0)
 
-	ec.aggregator = AggregatorFactory(_type, name)
+	ec.aggregator = AggregatorFactory(_type, kind, name, nil)
+	ec.quorum = new(Quorum)
 
 	return
 }
 
-func (ec *EntityCollecton) Result() (Entity, error) {
-	return ec.aggregator.Result()
+// SetParams configures the collection with params of its meta
+func (ec *EntityCollecton) SetParams(params []byte) error {
+	quorum, err := ParseQuorum(params)
+	if err != nil {
+		return err
+	}
+
+	ec.params = params
+	ec.quorum = quorum
+	// aggregators are configured by params too
+	ec.aggregator = AggregatorFactory(ec._type, ec.aggregatorKind, ec.EntityName, params)
+	return nil
 }
 
-func (ec *EntityCollecton) MergeMany(es []Entity) {
-	ec.Entities = append(ec.Entities, es...)
+func (ec *EntityCollecton) Result(t int64) (Entity, error) {
+	if !ec.quorum.Reached(ec.Entities) {
+		return nil, ErrQuorumNotReached
+	}
+	return ec.aggregator.Result(t)
+}
 
+// MergeMany merges entities into the collection, the collection window
+// [MinTick, MaxTick] follows the ticks of pending entities. Pending entities
+// mirror the aggregation round in state, outliers are only rejected on chain
+// by `Aggregate`, which records them in the aggregation cache.
+func (ec *EntityCollecton) MergeMany(es []Entity) {
 	for _, e := range es {
+		if len(ec.Entities) == 0 {
+			ec.MinTick = e.Tick()
+		}
+		if e.Tick() > ec.MaxTick {
+			ec.MaxTick = e.Tick()
+		}
+
+		ec.Entities = append(ec.Entities, e)
 		ec.aggregator.MergeOne(e)
 	}
 }
 
+func (ec *EntityCollecton) updateMinTick() {
+	if len(ec.Entities) == 0 {
+		ec.MinTick = ec.MaxTick
+		return
+	}
+
+	ec.MinTick = ec.Entities[0].Tick()
+}
+
 func (ec *EntityCollecton) RemoveMany(count int) {
 	length := len(ec.Entities)
 
@@ -208,6 +503,8 @@ func (ec *EntityCollecton) RemoveMany(count int) {
 		x, ec.Entities = ec.Entities[0], ec.Entities[1:]
 		ec.aggregator.RemoveOne(x)
 	}
+
+	ec.updateMinTick()
 }
 
 func (ec *EntityCollecton) RemoveBeforeTick(t int64) {
@@ -224,17 +521,104 @@ func (ec *EntityCollecton) RemoveBeforeTick(t int64) {
 			break
 		}
 	}
+
+	ec.updateMinTick()
+}
+
+// RemovePublisher removes pending entities of [publisher] from the collection
+func (ec *EntityCollecton) RemovePublisher(publisher string) {
+	kept := make([]Entity, 0, len(ec.Entities))
+	for _, e := range ec.Entities {
+		if e.Publisher() == publisher {
+			ec.aggregator.RemoveOne(e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	ec.Entities = kept
+
+	ec.updateMinTick()
 }
 
 func (ec *EntityCollecton) Clear() {
 	ec.Entities = make([]Entity, 0)
-	ec.aggregator = AggregatorFactory(ec._type, ec.EntityName)
+	ec.MinTick = ec.MaxTick
+	ec.aggregator = AggregatorFactory(ec._type, ec.aggregatorKind, ec.EntityName, ec.params)
 }
 
 type EntityCollectionMeta struct {
 	EntityName string `json:"name"`
 	EntityID   uint64 `json:"id"`
 	EntityType uint64 `json:"type"`
+	// aggregation rule, see [AggregatorFactory]
+	Aggregator uint64 `json:"aggregator"`
+	Params     []byte `json:"params"`
+}
+
+func (ecm *EntityCollectionMeta) Verify() error {
+	if len(ecm.EntityName) == 0 || len(ecm.EntityName) > consts.EntityNameMaxLen {
+		return ErrInvalidEntityName
+	}
+
+	if len(ecm.Params) > consts.EntityParamsMaxLen {
+		return ErrEntityParamsTooLarge
+	}
+
+	if !IsSupportedAggregator(ecm.EntityType, ecm.Aggregator) {
+		return ErrNotSupportedAggregator
+	}
+
+	if _, err := ParseOutlierFilter(ecm.Params); err != nil {
+		return err
+	}
+
+	if _, err := ParseQuorum(ecm.Params); err != nil {
+		return err
+	}
+
+	if _, err := ParseQueryPricing(ecm.Params); err != nil {
+		return err
+	}
+
+	if et, ok := LookupEntityType(ecm.EntityType); ok && et.VerifyParams != nil {
+		return et.VerifyParams(ecm.Params)
+	}
+
+	return nil
+}
+
+// Accepts checks [e] uploaded to the collection against its type specific
+// rules
+func (ecm *EntityCollectionMeta) Accepts(e Entity) error {
+	et, ok := LookupEntityType(ecm.EntityType)
+	if !ok {
+		return ErrNotSupportedEntity
+	}
+	if et.Accepts == nil {
+		return nil
+	}
+
+	return et.Accepts(e, ecm)
+}
+
+func (ecm *EntityCollectionMeta) Marshal(p *codec.Packer) {
+	p.PackString(ecm.EntityName)
+	p.PackUint64(ecm.EntityID)
+	p.PackUint64(ecm.EntityType)
+	p.PackUint64(ecm.Aggregator)
+	p.PackBytes(ecm.Params)
+}
+
+func UnmarshalEntityCollectionMeta(p *codec.Packer) (*EntityCollectionMeta, error) {
+	ecm := new(EntityCollectionMeta)
+	ecm.EntityName = p.UnpackString(true)
+	// all of them can be 0
+	ecm.EntityID = p.UnpackUint64(false)
+	ecm.EntityType = p.UnpackUint64(false)
+	ecm.Aggregator = p.UnpackUint64(false)
+	p.UnpackBytes(consts.EntityParamsMaxLen, false, &ecm.Params)
+
+	return ecm, p.Err()
 }
 
 type AggregationHistory struct {
@@ -278,6 +662,18 @@ func (ah *AggregationHistory) Push(e Entity) {
 	ah.Length += 1
 }
 
+// Latest returns the most recent aggregation result, nil if history is empty
+func (ah *AggregationHistory) Latest() Entity {
+	ah.l.RLock()
+	defer ah.l.RUnlock()
+
+	if ah.Length == 0 {
+		return nil
+	}
+
+	return ah.History[ah.Length-1]
+}
+
 func (ah *AggregationHistory) Count() uint64 {
 	ah.l.RLock()
 	defer ah.l.RUnlock()
@@ -292,28 +688,45 @@ type Oracle struct {
 	oracles map[uint64]*EntityCollecton
 	history map[uint64]*AggregationHistory
 	counter uint64
+	// initial tick of collection windows
+	t int64
 }
 
-func NewOracle(c Controller, t int64, trackedStocks []string) *Oracle {
+func NewOracle(c Controller, t int64, metas []*EntityCollectionMeta) (*Oracle, error) {
 	res := new(Oracle)
 
 	res.c = c
 	res.oracles = make(map[uint64]*EntityCollecton)
 	res.history = make(map[uint64]*AggregationHistory)
 	res.counter = 0
+	res.t = t
 
-	sort.Strings(trackedStocks)
+	for _, meta := range metas {
+		if err := res.AddEntityCollection(meta); err != nil {
+			return nil, err
+		}
+	}
 
-	res.counter = 0
+	return res, nil
+}
 
-	for _, ticker := range trackedStocks {
-		res.oracles[res.counter] = NewEntityCollection(t, res.counter, StockID, ticker)
-		res.history[res.counter] = NewAggregationHistory()
+// AddEntityCollection tracks a registered entity collection, collections
+// must be added in the order of their index
+func (o *Oracle) AddEntityCollection(meta *EntityCollectionMeta) error {
+	if meta.EntityID != o.counter {
+		return ErrUnexpectedEntityIndex
+	}
 
-		res.counter += 1
+	collection := NewEntityCollection(o.t, meta.EntityID, meta.EntityType, meta.Aggregator, meta.EntityName)
+	if err := collection.SetParams(meta.Params); err != nil {
+		return err
 	}
 
-	return res
+	o.oracles[o.counter] = collection
+	o.history[o.counter] = NewAggregationHistory()
+	o.counter += 1
+
+	return nil
 }
 
 func (o *Oracle) ClearEntityCollection() {
@@ -323,16 +736,66 @@ func (o *Oracle) ClearEntityCollection() {
 	}
 }
 
-func (o *Oracle) ClearOracleNSaveHistory() {
-	var i uint64
-	for i = 0; i < o.counter; i++ {
-		agg, err := o.oracles[i].Result()
-		if err != nil {
-			continue
-		}
-		o.history[i].Push(agg)
-		o.oracles[i].Clear()
+// SaveAggregationResult records an aggregation result computed on chain and
+// clears pending entities of that collection
+func (o *Oracle) SaveAggregationResult(id uint64, e Entity) error {
+	if id >= o.counter {
+		return ErrOutOfEntityCollectionRange
 	}
+
+	o.history[id].Push(e)
+	o.oracles[id].Clear()
+
+	return nil
+}
+
+// DiscardPendingEntities clears pending entities of a collection whose round
+// closed without publishing a result
+func (o *Oracle) DiscardPendingEntities(id uint64) error {
+	if id >= o.counter {
+		return ErrOutOfEntityCollectionRange
+	}
+
+	o.oracles[id].Clear()
+
+	return nil
+}
+
+// RestoreAggregationResult pushes a persisted aggregation result into history,
+// results not newer than the latest one in history are ignored
+func (o *Oracle) RestoreAggregationResult(id uint64, _type uint64, e Entity) error {
+	if id >= o.counter {
+		return ErrOutOfEntityCollectionRange
+	}
+
+	if _type != o.oracles[id]._type {
+		return ErrUnexpectedEntityType
+	}
+
+	if latest := o.history[id].Latest(); latest != nil && latest.Tick() >= e.Tick() {
+		return nil
+	}
+
+	o.history[id].Push(e)
+
+	return nil
+}
+
+// RestorePendingEntities replaces pending entities of a collection with
+// entities of the aggregation round persisted in state
+func (o *Oracle) RestorePendingEntities(id uint64, _type uint64, es []Entity) error {
+	if id >= o.counter {
+		return ErrOutOfEntityCollectionRange
+	}
+
+	if _type != o.oracles[id]._type {
+		return ErrUnexpectedEntityType
+	}
+
+	o.oracles[id].Clear()
+	o.oracles[id].MergeMany(es)
+
+	return nil
 }
 
 func (o *Oracle) InsertEntity(id uint64, _type uint64, e Entity) error {
@@ -344,6 +807,8 @@ func (o *Oracle) InsertEntity(id uint64, _type uint64, e Entity) error {
 		return ErrUnexpectedEntityType
 	}
 
+	// a publisher holds one entity per round, later uploads replace it
+	o.oracles[id].RemovePublisher(e.Publisher())
 	o.oracles[id].MergeMany([]Entity{e})
 
 	return nil
@@ -357,11 +822,11 @@ func (o *Oracle) GetEntityMeta(id uint64) (uint64, uint64, error) {
 	return o.oracles[id].EntityID, o.oracles[id]._type, nil
 }
 
-func (o *Oracle) GetAggregatedResult(id uint64) (Entity, error) {
+func (o *Oracle) GetAggregatedResult(id uint64, t int64) (Entity, error) {
 	if id > o.counter {
 		return nil, ErrOutOfEntityCollectionRange
 	}
-	return o.oracles[id].Result()
+	return o.oracles[id].Result(t)
 }
 
 func (o *Oracle) Counter() uint64 {
@@ -385,6 +850,8 @@ func (o *Oracle) GetAvailableEntities() []*EntityCollectionMeta {
 			EntityName: o.oracles[index].EntityName,
 			EntityID:   o.oracles[index].EntityID,
 			EntityType: o.oracles[index]._type,
+			Aggregator: o.oracles[index].aggregatorKind,
+			Params:     o.oracles[index].params,
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/bianyuanop/oraclevm/oracle"
 )
 
@@ -20,11 +21,15 @@ func TestGetEntityCollectionMetas(t *testing.T) {
 		logger: logging.NoLog{},
 	}
 
-	trackedPairs := make([]string, 2)
-	trackedPairs[0] = "Apple"
-	trackedPairs[0] = "AMD"
+	metas := []*oracle.EntityCollectionMeta{
+		{EntityName: "Apple", EntityID: 0, EntityType: oracle.StockID},
+		{EntityName: "AMD", EntityID: 1, EntityType: oracle.StockID},
+	}
 
-	o := oracle.NewOracle(&controller, 0, trackedPairs)
+	o, err := oracle.NewOracle(&controller, 0, metas)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	ecms := o.GetAvailableEntities()
 	if len(ecms) != 2 {
@@ -42,4 +47,76 @@ func TestGetEntityCollectionMetas(t *testing.T) {
 		t.Errorf("Unexpected entity meta: %+v(real)", amd)
 	}
 
+	if err := o.AddEntityCollection(&oracle.EntityCollectionMeta{EntityName: "Intel", EntityID: 3}); err != oracle.ErrUnexpectedEntityIndex {
+		t.Errorf("collections should be added in index order: %+v", err)
+	}
+}
+
+func TestRestoreAggregationResult(t *testing.T) {
+	controller := Controller{
+		logger: logging.NoLog{},
+	}
+
+	o, err := oracle.NewOracle(&controller, 0, []*oracle.EntityCollectionMeta{
+		{EntityName: "Apple", EntityID: 0, EntityType: oracle.StockID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher := crypto.EmptyPublicKey
+
+	for _, tick := range []int64{100, 200, 200, 150} {
+		if err := o.RestoreAggregationResult(0, oracle.StockID, oracle.NewStock("Apple", 1000, publisher, tick)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := o.GetHistory(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Tick() != 100 || history[1].Tick() != 200 {
+		t.Errorf("unexpected restored history: %+v", history)
+	}
+
+	if err := o.RestorePendingEntities(0, oracle.StockID, []oracle.Entity{oracle.NewStock("Apple", 1000, publisher, 300)}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := o.GetAggregatedResult(0, 400)
+	if err != nil || result.(*oracle.Stock).Price != 1000 {
+		t.Errorf("unexpected pending aggregation: %+v, %+v", result, err)
+	}
+}
+
+func TestInsertEntityReplacesPublisher(t *testing.T) {
+	controller := Controller{
+		logger: logging.NoLog{},
+	}
+
+	o, err := oracle.NewOracle(&controller, 0, []*oracle.EntityCollectionMeta{
+		{EntityName: "Apple", EntityID: 0, EntityType: oracle.StockID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*oracle.Stock{
+		oracle.NewStock("Apple", 1000, crypto.EmptyPublicKey, 100),
+		oracle.NewStock("Apple", 3000, crypto.EmptyPublicKey, 110),
+		oracle.NewStock("Apple", 2000, priv.PublicKey(), 120),
+	} {
+		if err := o.InsertEntity(0, oracle.StockID, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the later entity of a publisher replaces its earlier one
+	result, err := o.GetAggregatedResult(0, 200)
+	if err != nil || result.(*oracle.Stock).Price != 2500 {
+		t.Errorf("unexpected pending aggregation: %+v, %+v", result, err)
+	}
 }
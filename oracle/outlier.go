@@ -0,0 +1,112 @@
+package oracle
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// OutlierFilter rejects entities deviating too far from the median of an
+// entity collection, it is configured by the params of the collection
+type OutlierFilter struct {
+	// basis points from the median, 0 disables
+	MaxDeviation uint64 `json:"maxDeviation"`
+	// median absolute deviations from the median, 0 disables
+	MaxMADs uint64 `json:"maxMads"`
+}
+
+// ParseOutlierFilter decodes collection params, empty params disable the filter
+func ParseOutlierFilter(params []byte) (*OutlierFilter, error) {
+	f := new(OutlierFilter)
+	if len(params) == 0 {
+		return f, nil
+	}
+	if err := json.Unmarshal(params, f); err != nil {
+		return nil, ErrInvalidParams
+	}
+
+	return f, nil
+}
+
+func (f *OutlierFilter) Enabled() bool {
+	return f.MaxDeviation > 0 || f.MaxMADs > 0
+}
+
+// Outliers returns indices of [es] to reject, the median is taken over both
+// [reference] and [es]. Entities [aggregator] can't measure are never rejected.
+func (f *OutlierFilter) Outliers(aggregator EntityAggregator, reference []Entity, es []Entity) []int {
+	outliers := make([]int, 0)
+	if !f.Enabled() {
+		return outliers
+	}
+
+	values := make([]uint64, 0, len(reference)+len(es))
+	for _, e := range append(reference[:len(reference):len(reference)], es...) {
+		if v, ok := aggregator.Measure(e); ok {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return outliers
+	}
+
+	m := median(values)
+	deviations := make([]uint64, len(values))
+	for i, v := range values {
+		deviations[i] = distance(v, m)
+	}
+	mad := median(deviations)
+
+	for i, e := range es {
+		v, ok := aggregator.Measure(e)
+		if !ok {
+			continue
+		}
+		d := distance(v, m)
+		if f.MaxDeviation > 0 && deviation(v, m) > f.MaxDeviation {
+			outliers = append(outliers, i)
+			continue
+		}
+		// all values equal the median when MAD is 0, which rejects nothing
+		if f.MaxMADs > 0 && mad > 0 && mad <= math.MaxUint64/f.MaxMADs && d > mad*f.MaxMADs {
+			outliers = append(outliers, i)
+		}
+	}
+
+	return outliers
+}
+
+// median sorts [values] in place, the mean of the two middle values is taken
+// for even length
+func median(values []uint64) uint64 {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	lo, hi := values[n/2-1], values[n/2]
+	return lo + (hi-lo)/2
+}
+
+func distance(a uint64, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// deviation returns the distance between [v] and [ref] in basis points of [ref]
+func deviation(v uint64, ref uint64) uint64 {
+	diff := distance(v, ref)
+	if diff == 0 {
+		return 0
+	}
+	if ref == 0 || diff > math.MaxUint64/consts.BasisPoints {
+		return math.MaxUint64
+	}
+
+	return diff * consts.BasisPoints / ref
+}
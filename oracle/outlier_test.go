@@ -0,0 +1,85 @@
+package oracle_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestParseOutlierFilter(t *testing.T) {
+	f, err := oracle.ParseOutlierFilter(nil)
+	if err != nil || f.Enabled() {
+		t.Errorf("empty params should disable the filter: %+v, %+v", f, err)
+	}
+
+	f, err = oracle.ParseOutlierFilter([]byte(`{"maxDeviation":1000,"maxMads":3}`))
+	if err != nil || f.MaxDeviation != 1000 || f.MaxMADs != 3 {
+		t.Errorf("error parsing filter: %+v, %+v", f, err)
+	}
+
+	if _, err := oracle.ParseOutlierFilter([]byte(`not json`)); err != oracle.ErrInvalidParams {
+		t.Errorf("invalid params should fail: %+v", err)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	prices := []uint64{1000, 1010, 990, 1005, 5000}
+	entities := make([]oracle.Entity, len(prices))
+	for i, price := range prices {
+		entities[i] = oracle.NewStock("Stock-1", price, crypto.EmptyPublicKey, 0)
+	}
+	aggregator := oracle.NewStockAggregator("Stock-1")
+
+	// median is 1005
+	byDeviation := &oracle.OutlierFilter{MaxDeviation: 1000}
+	if outliers := byDeviation.Outliers(aggregator, nil, entities); !reflect.DeepEqual(outliers, []int{4}) {
+		t.Errorf("unexpected outliers: %v", outliers)
+	}
+
+	// MAD is 5
+	byMAD := &oracle.OutlierFilter{MaxMADs: 1}
+	if outliers := byMAD.Outliers(aggregator, nil, entities); !reflect.DeepEqual(outliers, []int{2, 4}) {
+		t.Errorf("unexpected outliers: %v", outliers)
+	}
+
+	result, outliers, err := oracle.Aggregate(oracle.StockID, oracle.MeanAggregatorID, nil, byDeviation, 0, entities, nil)
+	if err != nil || result.(*oracle.Stock).Price != 1001 || len(outliers) != 1 {
+		t.Errorf("error aggregation: %+v, %v, %+v", result, outliers, err)
+	}
+}
+
+func TestCollectionKeepOutliers(t *testing.T) {
+	collection := oracle.NewEntityCollection(0, 0, oracle.StockID, oracle.MeanAggregatorID, "Stock-1")
+	if err := collection.SetParams([]byte(`{"maxDeviation":1000}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// pending entities mirror the round, outliers are rejected by `Aggregate`
+	for _, price := range []uint64{1000, 1010, 990, 2000} {
+		collection.MergeMany([]oracle.Entity{oracle.NewStock("Stock-1", price, crypto.EmptyPublicKey, 0)})
+	}
+	if len(collection.Entities) != 4 {
+		t.Errorf("unexpected pending entities: %d", len(collection.Entities))
+	}
+
+	result, err := collection.Result(0)
+	if err != nil || result.(*oracle.Stock).Price != 1250 {
+		t.Errorf("error aggregation: %+v, %+v", result, err)
+	}
+}
+
+func TestAggregateRejectEveryEntity(t *testing.T) {
+	// median is 150, both deviate 3333 basis points
+	entities := []oracle.Entity{
+		oracle.NewStock("Stock-1", 100, crypto.EmptyPublicKey, 0),
+		oracle.NewStock("Stock-1", 200, crypto.EmptyPublicKey, 0),
+	}
+	filter := &oracle.OutlierFilter{MaxDeviation: 1000}
+
+	result, outliers, err := oracle.Aggregate(oracle.StockID, oracle.MeanAggregatorID, nil, filter, 0, entities, nil)
+	if err != oracle.ErrNoAcceptedEntities || result != nil || !reflect.DeepEqual(outliers, []int{0, 1}) {
+		t.Errorf("unexpected aggregation: %+v, %v, %+v", result, outliers, err)
+	}
+}
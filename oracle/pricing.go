@@ -0,0 +1,24 @@
+package oracle
+
+import "encoding/json"
+
+// QueryPricing charges on chain queries of a collection, it is configured by
+// the params of the collection
+type QueryPricing struct {
+	// charged from the prepaid balance of the querying chain, 0 serves
+	// queries for free
+	QueryFee uint64 `json:"queryFee"`
+}
+
+// ParseQueryPricing decodes collection params, empty params charge no fee
+func ParseQueryPricing(params []byte) (*QueryPricing, error) {
+	qp := new(QueryPricing)
+	if len(params) == 0 {
+		return qp, nil
+	}
+	if err := json.Unmarshal(params, qp); err != nil {
+		return nil, ErrInvalidParams
+	}
+
+	return qp, nil
+}
@@ -0,0 +1,23 @@
+package oracle_test
+
+import (
+	"testing"
+
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestQueryPricing(t *testing.T) {
+	qp, err := oracle.ParseQueryPricing(nil)
+	if err != nil || qp.QueryFee != 0 {
+		t.Errorf("empty params should charge no fee: %+v, %+v", qp, err)
+	}
+
+	qp, err = oracle.ParseQueryPricing([]byte(`{"minPublishers":2,"queryFee":100}`))
+	if err != nil || qp.QueryFee != 100 {
+		t.Fatalf("error parsing query pricing: %+v, %+v", qp, err)
+	}
+
+	if _, err := oracle.ParseQueryPricing([]byte(`{"queryFee":-1}`)); err != oracle.ErrInvalidParams {
+		t.Errorf("negative fee should fail: %+v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package oracle
+
+// PushThreshold decides when aggregation results are pushed to a subscriber
+type PushThreshold struct {
+	// deviation in basis points from the last pushed value, 0 disables
+	Deviation uint64 `json:"deviation"`
+	// max interval in milliseconds between pushes, 0 disables
+	Heartbeat int64 `json:"heartbeat"`
+}
+
+// Crossed reports whether a result of [value] aggregated at [t] must be
+// pushed, given the last pushed [lastValue] at [lastTick], 0 if never pushed.
+// Results without a value, see [Observe], are only pushed on heartbeat.
+func (pt *PushThreshold) Crossed(lastValue uint64, lastTick int64, value uint64, observed bool, t int64) bool {
+	if lastTick == 0 {
+		return true
+	}
+	if pt.Heartbeat > 0 && t-lastTick >= pt.Heartbeat {
+		return true
+	}
+	if pt.Deviation == 0 || !observed {
+		return false
+	}
+
+	return deviation(value, lastValue) >= pt.Deviation
+}
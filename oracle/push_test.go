@@ -0,0 +1,40 @@
+package oracle_test
+
+import (
+	"testing"
+
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestPushThreshold(t *testing.T) {
+	pt := &oracle.PushThreshold{Deviation: 100, Heartbeat: 1000} // 1%, 1s
+
+	if !pt.Crossed(0, 0, 1000, true, 10) {
+		t.Errorf("first result should be pushed")
+	}
+	if pt.Crossed(1000, 10, 1005, true, 500) {
+		t.Errorf("result within deviation and heartbeat should not be pushed")
+	}
+	if !pt.Crossed(1000, 10, 1010, true, 500) {
+		t.Errorf("result deviating 1%% should be pushed")
+	}
+	if !pt.Crossed(1000, 10, 990, true, 500) {
+		t.Errorf("result deviating -1%% should be pushed")
+	}
+	if !pt.Crossed(1000, 10, 1000, true, 1010) {
+		t.Errorf("result after heartbeat should be pushed")
+	}
+
+	// results without value are pushed on heartbeat only
+	if pt.Crossed(1000, 10, 0, false, 500) {
+		t.Errorf("result without value should not be pushed before heartbeat")
+	}
+	if !pt.Crossed(1000, 10, 0, false, 1010) {
+		t.Errorf("result without value should be pushed on heartbeat")
+	}
+
+	disabled := &oracle.PushThreshold{}
+	if disabled.Crossed(1000, 10, 2000, true, 100_000) {
+		t.Errorf("disabled thresholds should only push the first result")
+	}
+}
@@ -0,0 +1,47 @@
+package oracle
+
+import (
+	"encoding/json"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// Quorum requires entities from a minimum number of distinct publishers
+// before an aggregation result is published, it is configured by the params
+// of the collection
+type Quorum struct {
+	// 0 and 1 publish results of a single publisher
+	MinPublishers uint64 `json:"minPublishers"`
+}
+
+// ParseQuorum decodes collection params, empty params require no quorum
+func ParseQuorum(params []byte) (*Quorum, error) {
+	q := new(Quorum)
+	if len(params) == 0 {
+		return q, nil
+	}
+	if err := json.Unmarshal(params, q); err != nil {
+		return nil, ErrInvalidParams
+	}
+	// a round can't hold more publishers than submissions
+	if q.MinPublishers > consts.RoundMaxSubmissions {
+		return nil, ErrInvalidParams
+	}
+
+	return q, nil
+}
+
+// Reached reports whether [es] are published by enough distinct publishers
+func (q *Quorum) Reached(es []Entity) bool {
+	return uint64(CountPublishers(es)) >= q.MinPublishers
+}
+
+// CountPublishers returns the number of distinct publishers of [es]
+func CountPublishers(es []Entity) int {
+	publishers := make(map[string]struct{}, len(es))
+	for _, e := range es {
+		publishers[e.Publisher()] = struct{}{}
+	}
+
+	return len(publishers)
+}
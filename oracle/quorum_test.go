@@ -0,0 +1,44 @@
+package oracle_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestQuorum(t *testing.T) {
+	q, err := oracle.ParseQuorum(nil)
+	if err != nil || q.MinPublishers != 0 {
+		t.Errorf("empty params should require no quorum: %+v, %+v", q, err)
+	}
+
+	q, err = oracle.ParseQuorum([]byte(`{"maxDeviation":1000,"minPublishers":2}`))
+	if err != nil || q.MinPublishers != 2 {
+		t.Fatalf("error parsing quorum: %+v, %+v", q, err)
+	}
+
+	if _, err := oracle.ParseQuorum([]byte(`{"minPublishers":100000}`)); err != oracle.ErrInvalidParams {
+		t.Errorf("quorum beyond round capacity should fail: %+v", err)
+	}
+
+	priv, err := crypto.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := crypto.EmptyPublicKey, priv.PublicKey()
+
+	// submissions of one publisher count once
+	es := []oracle.Entity{
+		oracle.NewStock("INTC", 30, a, 0),
+		oracle.NewStock("INTC", 31, a, 1),
+	}
+	if q.Reached(es) {
+		t.Errorf("quorum should not be reached by one publisher")
+	}
+
+	es = append(es, oracle.NewStock("INTC", 32, b, 2))
+	if !q.Reached(es) {
+		t.Errorf("quorum should be reached by two publishers")
+	}
+}
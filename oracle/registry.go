@@ -0,0 +1,85 @@
+package oracle
+
+import (
+	"sync"
+
+	"github.com/ava-labs/hypersdk/crypto"
+)
+
+// AggregatorConstructor creates an aggregator for the collection [name]
+// configured by [params]
+type AggregatorConstructor func(name string, params []byte) EntityAggregator
+
+// EntityType describes an entity kind known by the oracle. Types are
+// registered with [RegisterEntityType] by the package implementing them,
+// before the VM is initialized. Type ids are part of chain state, so every
+// node must register the same types.
+type EntityType struct {
+	ID   uint64
+	Name string
+
+	// Decode restores an entity from [payload] submitted by [publisher] at
+	// block timestamp [tick]
+	Decode func(payload []byte, publisher crypto.PublicKey, tick int64) (Entity, error)
+	// Validate checks entities uploaded by publishers, nil accepts every
+	// decoded entity
+	Validate func(Entity) error
+	// Accepts checks entities uploaded to the collection [meta], nil accepts
+	// entities of every collection
+	Accepts func(e Entity, meta *EntityCollectionMeta) error
+	// VerifyParams checks type specific params of collections at
+	// registration, nil accepts any params
+	VerifyParams func(params []byte) error
+	// Aggregators selectable by collections of the type, keyed by aggregator
+	// kind
+	Aggregators map[uint64]AggregatorConstructor
+	// Deviation returns how far an entity deviates from the aggregation
+	// result in basis points, nil if entities can't be compared
+	Deviation func(e Entity, result Entity) (uint64, error)
+	// Measure returns the value of aggregation results recorded as
+	// [Observation], nil if results can't be observed
+	Measure func(Entity) (uint64, bool)
+	// WithValue returns a copy of the aggregation result [latest] carrying
+	// the measured [value] at [tick], nil if results can't be averaged
+	WithValue func(latest Entity, value uint64, tick int64) (Entity, error)
+}
+
+var (
+	entityTypesL sync.RWMutex
+	entityTypes  = make(map[uint64]*EntityType)
+)
+
+// RegisterEntityType makes [et] available to entity collections, ids can
+// only be registered once
+func RegisterEntityType(et *EntityType) error {
+	if len(et.Name) == 0 || et.Decode == nil {
+		return ErrInvalidEntityType
+	}
+
+	entityTypesL.Lock()
+	defer entityTypesL.Unlock()
+
+	if _, ok := entityTypes[et.ID]; ok {
+		return ErrDuplicateEntityType
+	}
+	entityTypes[et.ID] = et
+
+	return nil
+}
+
+// MustRegisterEntityType is [RegisterEntityType] panicking on errors, used to
+// register types from `init`
+func MustRegisterEntityType(et *EntityType) {
+	if err := RegisterEntityType(et); err != nil {
+		panic(err)
+	}
+}
+
+// LookupEntityType returns the registered type of [id]
+func LookupEntityType(id uint64) (*EntityType, bool) {
+	entityTypesL.RLock()
+	defer entityTypesL.RUnlock()
+
+	et, ok := entityTypes[id]
+	return et, ok
+}
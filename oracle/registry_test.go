@@ -0,0 +1,103 @@
+package oracle_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+// counter is an entity registered outside of the oracle package
+type counter struct {
+	value uint64
+	tick  int64
+}
+
+func (*counter) Publisher() string { return "" }
+func (c *counter) Tick() int64     { return c.tick }
+func (c *counter) Marshal() ([]byte, error) {
+	return []byte(strconv.FormatUint(c.value, 10)), nil
+}
+
+type counterAggregator struct {
+	sum uint64
+}
+
+func (ca *counterAggregator) Result(t int64) (oracle.Entity, error) {
+	return &counter{value: ca.sum, tick: t}, nil
+}
+func (ca *counterAggregator) MergeOne(e oracle.Entity)  { ca.sum += e.(*counter).value }
+func (ca *counterAggregator) RemoveOne(e oracle.Entity) { ca.sum -= e.(*counter).value }
+func (*counterAggregator) Measure(oracle.Entity) (uint64, bool) {
+	return 0, false
+}
+
+const counterID = 100
+
+func TestRegisterEntityType(t *testing.T) {
+	counterType := &oracle.EntityType{
+		ID:   counterID,
+		Name: "counter",
+		Decode: func(payload []byte, _ crypto.PublicKey, tick int64) (oracle.Entity, error) {
+			value, err := strconv.ParseUint(string(payload), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return &counter{value: value, tick: tick}, nil
+		},
+		Aggregators: map[uint64]oracle.AggregatorConstructor{
+			oracle.MeanAggregatorID: func(string, []byte) oracle.EntityAggregator { return new(counterAggregator) },
+		},
+		Measure: func(e oracle.Entity) (uint64, bool) {
+			return e.(*counter).value, true
+		},
+	}
+	if err := oracle.RegisterEntityType(counterType); err != nil {
+		t.Fatalf("register failed: %+v", err)
+	}
+	if err := oracle.RegisterEntityType(counterType); err != oracle.ErrDuplicateEntityType {
+		t.Errorf("expected duplicate type, got %+v", err)
+	}
+	if err := oracle.RegisterEntityType(&oracle.EntityType{ID: counterID + 1}); err != oracle.ErrInvalidEntityType {
+		t.Errorf("expected invalid type, got %+v", err)
+	}
+
+	if name := oracle.EntityIDToTypeString(counterID); name != "counter" {
+		t.Errorf("unexpected type name %s", name)
+	}
+	if !oracle.IsSupportedAggregator(counterID, oracle.MeanAggregatorID) || oracle.IsSupportedAggregator(counterID, oracle.MedianAggregatorID) {
+		t.Error("unexpected aggregators of registered type")
+	}
+	if _, err := oracle.UnmarshalEntity(counterID, []byte("x")); err != oracle.ErrMarshalEntityFailed {
+		t.Errorf("expected decoding failure, got %+v", err)
+	}
+
+	collection := oracle.NewEntityCollection(0, 0, counterID, oracle.MeanAggregatorID, "counter")
+	for _, payload := range []string{"3", "4"} {
+		e, err := oracle.RestoreEntity(counterID, crypto.EmptyPublicKey, 10, []byte(payload))
+		if err != nil {
+			t.Fatalf("restore failed: %+v", err)
+		}
+		collection.MergeMany([]oracle.Entity{e})
+	}
+	res, err := collection.Result(20)
+	if err != nil {
+		t.Fatalf("aggregation failed: %+v", err)
+	}
+	if value, ok := oracle.Observe(counterID, res); !ok || value != 7 {
+		t.Errorf("unexpected result %d", value)
+	}
+	if _, err := oracle.Deviation(counterID, res, res); err != oracle.ErrNotSupportedEntity {
+		t.Errorf("expected deviation to be unsupported, got %+v", err)
+	}
+
+	payload, err := oracle.NewEntityWithMeta(counterID, 0, res).Marshal()
+	if err != nil {
+		t.Fatalf("encoding failed: %+v", err)
+	}
+	restored, err := oracle.UnmarshalEntityWithMeta(payload)
+	if err != nil || restored.Entity.(*counter).value != 7 {
+		t.Errorf("unexpected entity with meta %+v, %+v", restored, err)
+	}
+}
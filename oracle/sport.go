@@ -0,0 +1,271 @@
+package oracle
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
+	"github.com/ava-labs/hypersdk/crypto"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// statuses of sport events reported by publishers
+const (
+	SportScheduled = "scheduled"
+	SportLive      = "live"
+	SportFinished  = "finished"
+	SportCancelled = "cancelled"
+)
+
+func init() {
+	MustRegisterEntityType(&EntityType{
+		ID:   SportID,
+		Name: "sport",
+		Decode: func(payload []byte, publisher crypto.PublicKey, tick int64) (Entity, error) {
+			s, err := UnmarshalSport(payload)
+			if err != nil {
+				return nil, err
+			}
+			s.publisher = publisher
+			s.tick = tick
+
+			return s, nil
+		},
+		Validate: func(e Entity) error {
+			s, ok := e.(*Sport)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+
+			return s.Verify()
+		},
+		Accepts: func(e Entity, meta *EntityCollectionMeta) error {
+			s, ok := e.(*Sport)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+			// collections are named by the event they settle
+			if s.EventID != meta.EntityName {
+				return NewFieldError("eventId", ErrNameMismatch)
+			}
+
+			return nil
+		},
+		Aggregators: map[uint64]AggregatorConstructor{
+			ConsensusAggregatorID: func(name string, _ []byte) EntityAggregator { return NewSportConsensusAggregator(name) },
+		},
+		Deviation: func(e Entity, result Entity) (uint64, error) {
+			s, ok := e.(*Sport)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+			ref, ok := result.(*Sport)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+
+			return s.Deviation(ref), nil
+		},
+	})
+}
+
+type Sport struct {
+	EventID   string `json:"eventId"`
+	HomeTeam  string `json:"homeTeam"`
+	AwayTeam  string `json:"awayTeam"`
+	HomeScore uint64 `json:"homeScore"`
+	AwayScore uint64 `json:"awayScore"`
+	Status    string `json:"status"`
+	// set once the outcome can be settled
+	Final bool `json:"final"`
+
+	publisher crypto.PublicKey
+	tick      int64
+}
+
+func NewSport(
+	eventID string,
+	homeTeam string,
+	awayTeam string,
+	homeScore uint64,
+	awayScore uint64,
+	status string,
+	final bool,
+	publisher crypto.PublicKey,
+	tick int64,
+) (s *Sport) {
+	s = new(Sport)
+	s.EventID = eventID
+	s.HomeTeam = homeTeam
+	s.AwayTeam = awayTeam
+	s.HomeScore = homeScore
+	s.AwayScore = awayScore
+	s.Status = status
+	s.Final = final
+	s.publisher = publisher
+	s.tick = tick
+
+	return s
+}
+
+func (s *Sport) Publisher() string {
+	return string(s.publisher[:])
+}
+
+func (s *Sport) Tick() int64 {
+	return s.tick
+}
+
+// Verify checks the fields required to settle the event
+func (s *Sport) Verify() error {
+	if err := verifyText("eventId", s.EventID); err != nil {
+		return err
+	}
+	if err := verifyText("homeTeam", s.HomeTeam); err != nil {
+		return err
+	}
+	if err := verifyText("awayTeam", s.AwayTeam); err != nil {
+		return err
+	}
+	switch s.Status {
+	case SportScheduled, SportLive:
+		if s.Final {
+			return NewFieldError("final", ErrUnfinishedEvent)
+		}
+		return nil
+	case SportFinished, SportCancelled:
+		return nil
+	default:
+		return NewFieldError("status", ErrInvalidSportStatus)
+	}
+}
+
+// Outcome identifies what [s] reports, publishers agree when their outcomes
+// are equal
+func (s *Sport) Outcome() string {
+	return fmt.Sprintf("%q %q %q %d %d %q %t", s.EventID, s.HomeTeam, s.AwayTeam, s.HomeScore, s.AwayScore, s.Status, s.Final)
+}
+
+// Deviation returns 0 when [s] reports the outcome of [ref], the whole
+// basis points otherwise
+func (s *Sport) Deviation(ref *Sport) uint64 {
+	if s.Outcome() == ref.Outcome() {
+		return 0
+	}
+	return consts.BasisPoints
+}
+
+// vote counts publishers reporting the same outcome
+type vote struct {
+	sport *Sport
+	count uint64
+}
+
+// SportConsensusAggregator settles the outcome reported by a strict majority
+// of merged entities
+type SportConsensusAggregator struct {
+	votes map[string]*vote
+	total uint64
+}
+
+func NewSportConsensusAggregator(name string) *SportConsensusAggregator {
+	res := new(SportConsensusAggregator)
+	res.votes = make(map[string]*vote)
+
+	return res
+}
+
+func (sca *SportConsensusAggregator) Result(t int64) (Entity, error) {
+	if sca.total == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	for _, v := range sca.votes {
+		if v.count*2 > sca.total {
+			res := *v.sport
+			res.publisher = crypto.EmptyPublicKey
+			res.tick = t
+
+			return &res, nil
+		}
+	}
+
+	return nil, ErrNoConsensus
+}
+
+// Measure never rejects sport entities as outliers, dissenting outcomes are
+// outvoted instead
+func (*SportConsensusAggregator) Measure(Entity) (uint64, bool) {
+	return 0, false
+}
+
+func (sca *SportConsensusAggregator) MergeOne(s Entity) {
+	sp, ok := s.(*Sport)
+	if !ok {
+		return
+	}
+
+	outcome := sp.Outcome()
+	v, ok := sca.votes[outcome]
+	if !ok {
+		v = &vote{sport: sp}
+		sca.votes[outcome] = v
+	}
+	v.count++
+	sca.total++
+}
+
+func (sca *SportConsensusAggregator) RemoveOne(s Entity) {
+	sp, ok := s.(*Sport)
+	if !ok {
+		return
+	}
+
+	outcome := sp.Outcome()
+	v, ok := sca.votes[outcome]
+	if !ok {
+		return
+	}
+	v.count--
+	sca.total--
+	if v.count == 0 {
+		delete(sca.votes, outcome)
+	}
+}
+
+func (s *Sport) String() string {
+	return fmt.Sprintf("%s %d - %d %s (%s)", s.HomeTeam, s.HomeScore, s.AwayScore, s.AwayTeam, s.Status)
+}
+
+func (s *Sport) Marshal() ([]byte, error) {
+	size := codec.StringLen(s.EventID) + codec.StringLen(s.HomeTeam) + codec.StringLen(s.AwayTeam) +
+		hconsts.Uint64Len*2 + codec.StringLen(s.Status) + hconsts.BoolLen
+	return encodeEntity(size, func(p *codec.Packer) {
+		p.PackString(s.EventID)
+		p.PackString(s.HomeTeam)
+		p.PackString(s.AwayTeam)
+		p.PackUint64(s.HomeScore)
+		p.PackUint64(s.AwayScore)
+		p.PackString(s.Status)
+		p.PackBool(s.Final)
+	})
+}
+
+func UnmarshalSport(payload []byte) (*Sport, error) {
+	var s Sport
+	err := decodeEntity(payload, &s, func(p *codec.Packer) {
+		s.EventID = p.UnpackString(false)
+		s.HomeTeam = p.UnpackString(false)
+		s.AwayTeam = p.UnpackString(false)
+		s.HomeScore = p.UnpackUint64(false)
+		s.AwayScore = p.UnpackUint64(false)
+		s.Status = p.UnpackString(false)
+		s.Final = p.UnpackBool()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,84 @@
+package oracle_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func newFinal(home uint64, away uint64) oracle.Entity {
+	return oracle.NewSport("match-1", "Home", "Away", home, away, oracle.SportFinished, true, crypto.EmptyPublicKey, 0)
+}
+
+func TestSportConsensusAggregate(t *testing.T) {
+	collection := oracle.NewEntityCollection(0, 0, oracle.SportID, oracle.ConsensusAggregatorID, "match-1")
+
+	collection.MergeMany([]oracle.Entity{newFinal(2, 1), newFinal(2, 1), newFinal(1, 1)})
+	res, err := collection.Result(100)
+	if err != nil {
+		t.Fatalf("error aggregation: %+v", err)
+	}
+	sport := res.(*oracle.Sport)
+	if sport.HomeScore != 2 || sport.AwayScore != 1 || !sport.Final || res.Tick() != 100 {
+		t.Errorf("unexpected outcome: %+v", sport)
+	}
+
+	// 2 of 4 is not a majority
+	collection.MergeMany([]oracle.Entity{newFinal(1, 1)})
+	if _, err := collection.Result(100); err != oracle.ErrNoConsensus {
+		t.Errorf("expected no consensus, got %+v", err)
+	}
+
+	collection.RemoveMany(1)
+	res, err = collection.Result(100)
+	if err != nil || res.(*oracle.Sport).HomeScore != 1 {
+		t.Errorf("expected outcome 1-1, got %+v, %+v", res, err)
+	}
+}
+
+func TestSportDeviation(t *testing.T) {
+	if d, err := oracle.Deviation(oracle.SportID, newFinal(2, 1), newFinal(2, 1)); err != nil || d != 0 {
+		t.Errorf("expected no deviation, got %d, %+v", d, err)
+	}
+	if d, err := oracle.Deviation(oracle.SportID, newFinal(1, 1), newFinal(2, 1)); err != nil || d != consts.BasisPoints {
+		t.Errorf("expected full deviation, got %d, %+v", d, err)
+	}
+}
+
+func TestUnmarshalSport(t *testing.T) {
+	e, err := oracle.UnmarshalEntity(oracle.SportID, marshal(t, newFinal(3, 0)))
+	if err != nil || e.(*oracle.Sport).HomeScore != 3 {
+		t.Errorf("unexpected entity: %+v, %+v", e, err)
+	}
+
+	for _, s := range []oracle.Entity{
+		oracle.NewSport("", "Home", "Away", 0, 0, oracle.SportFinished, true, crypto.EmptyPublicKey, 0),
+		oracle.NewSport("match-1", "Home", "Away", 0, 0, "postponed", false, crypto.EmptyPublicKey, 0),
+	} {
+		if _, err := oracle.UnmarshalEntity(oracle.SportID, marshal(t, s)); err == nil {
+			t.Errorf("expected %+v to be rejected", s)
+		}
+	}
+
+	if !oracle.IsSupportedAggregator(oracle.SportID, oracle.ConsensusAggregatorID) {
+		t.Error("consensus aggregator should support sport entities")
+	}
+	if oracle.IsSupportedAggregator(oracle.SportID, oracle.MeanAggregatorID) {
+		t.Error("mean aggregator should not support sport entities")
+	}
+}
+
+func TestSportAccepts(t *testing.T) {
+	meta := &oracle.EntityCollectionMeta{EntityName: "match-1", EntityType: oracle.SportID}
+	if err := meta.Accepts(newFinal(2, 1)); err != nil {
+		t.Errorf("unexpected rejection: %+v", err)
+	}
+	err := meta.Accepts(oracle.NewSport("match-2", "Home", "Away", 2, 1, oracle.SportFinished, true, crypto.EmptyPublicKey, 0))
+	var fe *oracle.FieldError
+	if !errors.Is(err, oracle.ErrNameMismatch) || !errors.As(err, &fe) || fe.Field != "eventId" {
+		t.Errorf("expected event id mismatch, got %+v", err)
+	}
+}
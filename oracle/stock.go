@@ -1,12 +1,79 @@
 package oracle
 
 import (
-	"encoding/json"
-	"time"
+	"fmt"
+	"math/big"
+	"sort"
 
+	"github.com/ava-labs/hypersdk/codec"
+	hconsts "github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/crypto"
 )
 
+func init() {
+	MustRegisterEntityType(&EntityType{
+		ID:   StockID,
+		Name: "stock",
+		Decode: func(payload []byte, publisher crypto.PublicKey, tick int64) (Entity, error) {
+			s, err := UnmarshalStock(payload)
+			if err != nil {
+				return nil, err
+			}
+			s.publisher = publisher
+			s.tick = tick
+
+			return s, nil
+		},
+		Aggregators: map[uint64]AggregatorConstructor{
+			MeanAggregatorID:           func(name string, _ []byte) EntityAggregator { return NewStockAggregator(name) },
+			MedianAggregatorID:         func(name string, _ []byte) EntityAggregator { return NewStockMedianAggregator(name) },
+			WeightedMeanAggregatorID:   func(name string, _ []byte) EntityAggregator { return NewStockWeightedAggregator(name) },
+			WeightedMedianAggregatorID: func(name string, _ []byte) EntityAggregator { return NewStockWeightedMedianAggregator(name) },
+		},
+		Deviation: func(e Entity, result Entity) (uint64, error) {
+			s, ok := e.(*Stock)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+			ref, ok := result.(*Stock)
+			if !ok {
+				return 0, ErrUnexpectedEntityType
+			}
+
+			return s.Deviation(ref), nil
+		},
+		Validate: func(e Entity) error {
+			s, ok := e.(*Stock)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+
+			return s.Verify()
+		},
+		Accepts: func(e Entity, meta *EntityCollectionMeta) error {
+			s, ok := e.(*Stock)
+			if !ok {
+				return ErrUnexpectedEntityType
+			}
+			// collections are named by the ticker they track
+			if s.Ticker != meta.EntityName {
+				return NewFieldError("ticker", ErrNameMismatch)
+			}
+
+			return nil
+		},
+		Measure: measureStock,
+		WithValue: func(latest Entity, value uint64, tick int64) (Entity, error) {
+			s, ok := latest.(*Stock)
+			if !ok {
+				return nil, ErrUnexpectedEntityType
+			}
+
+			return NewStock(s.Ticker, value, s.publisher, tick), nil
+		},
+	})
+}
+
 type Stock struct {
 	Ticker string `json:"ticker"`
 	Price  uint64 `json:"price"`
@@ -33,36 +100,68 @@ func (s *Stock) Tick() int64 {
 	return s.tick
 }
 
+// Verify checks the ticker of [s] is set and its price is positive
+func (s *Stock) Verify() error {
+	if err := verifyText("ticker", s.Ticker); err != nil {
+		return err
+	}
+	if s.Price == 0 {
+		return NewFieldError("price", ErrNotPositive)
+	}
+
+	return nil
+}
+
+// Deviation returns the distance between the price of [s] and [ref] in basis
+// points of the [ref] price
+func (s *Stock) Deviation(ref *Stock) uint64 {
+	return deviation(s.Price, ref.Price)
+}
+
+// measureStock returns the price compared by [OutlierFilter]
+func measureStock(e Entity) (uint64, bool) {
+	stk, ok := e.(*Stock)
+	if !ok {
+		return 0, false
+	}
+	return stk.Price, true
+}
+
 type StockAggregator struct {
 	ticker string
-	sum    uint64
-	count  uint64
+	// prices can sum beyond uint64
+	sum   *big.Int
+	count uint64
 }
 
 func NewStockAggregator(name string) *StockAggregator {
 	res := new(StockAggregator)
 	res.count = 0
-	res.sum = 0
+	res.sum = new(big.Int)
 	// empty at first
 	res.ticker = ""
 
 	return res
 }
 
-func (sa *StockAggregator) Result() (Entity, error) {
+func (sa *StockAggregator) Result(t int64) (Entity, error) {
 	res := new(Stock)
 	res.Ticker = sa.ticker
 	res.publisher = crypto.EmptyPublicKey
-	res.tick = time.Now().Unix()
+	res.tick = t
 
 	if sa.count != 0 {
-		res.Price = sa.sum / sa.count
+		res.Price = new(big.Int).Quo(sa.sum, new(big.Int).SetUint64(sa.count)).Uint64()
 		return res, nil
 	} else {
 		return nil, ErrZeroDenominator
 	}
 }
 
+func (*StockAggregator) Measure(e Entity) (uint64, bool) {
+	return measureStock(e)
+}
+
 func (sa *StockAggregator) MergeOne(s Entity) {
 
 	stk, ok := s.(*Stock)
@@ -73,7 +172,7 @@ func (sa *StockAggregator) MergeOne(s Entity) {
 	if sa.ticker == "" {
 		sa.ticker = stk.Ticker
 	}
-	sa.sum += stk.Price
+	sa.sum.Add(sa.sum, new(big.Int).SetUint64(stk.Price))
 	sa.count += 1
 }
 
@@ -82,20 +181,264 @@ func (sa *StockAggregator) RemoveOne(s Entity) {
 	if !ok {
 		return
 	}
-	sa.sum -= stk.Price
+	sa.sum.Sub(sa.sum, new(big.Int).SetUint64(stk.Price))
 	sa.count -= 1
 }
 
-func (s *Stock) Marshal() []byte {
-	// should always success
-	res, _ := json.Marshal(s)
+// StockMedianAggregator keeps merged prices sorted so that the median is
+// available after each `MergeOne`/`RemoveOne` without sorting again
+type StockMedianAggregator struct {
+	ticker string
+	prices []uint64
+}
+
+func NewStockMedianAggregator(name string) *StockMedianAggregator {
+	res := new(StockMedianAggregator)
+	res.prices = make([]uint64, 0)
+	// empty at first
+	res.ticker = ""
+
+	return res
+}
+
+func (sma *StockMedianAggregator) Result(t int64) (Entity, error) {
+	n := len(sma.prices)
+	if n == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	res := new(Stock)
+	res.Ticker = sma.ticker
+	res.publisher = crypto.EmptyPublicKey
+	res.tick = t
+
+	if n%2 == 1 {
+		res.Price = sma.prices[n/2]
+	} else {
+		// mean of the two middle prices without overflowing
+		lo, hi := sma.prices[n/2-1], sma.prices[n/2]
+		res.Price = lo + (hi-lo)/2
+	}
+
+	return res, nil
+}
+
+func (*StockMedianAggregator) Measure(e Entity) (uint64, bool) {
+	return measureStock(e)
+}
+
+func (sma *StockMedianAggregator) MergeOne(s Entity) {
+	stk, ok := s.(*Stock)
+	if !ok {
+		return
+	}
+
+	if sma.ticker == "" {
+		sma.ticker = stk.Ticker
+	}
+
+	i := sort.Search(len(sma.prices), func(i int) bool { return sma.prices[i] >= stk.Price })
+	sma.prices = append(sma.prices, 0)
+	copy(sma.prices[i+1:], sma.prices[i:])
+	sma.prices[i] = stk.Price
+}
+
+func (sma *StockMedianAggregator) RemoveOne(s Entity) {
+	stk, ok := s.(*Stock)
+	if !ok {
+		return
+	}
+
+	i := sort.Search(len(sma.prices), func(i int) bool { return sma.prices[i] >= stk.Price })
+	if i == len(sma.prices) || sma.prices[i] != stk.Price {
+		return
+	}
+	sma.prices = append(sma.prices[:i], sma.prices[i+1:]...)
+}
+
+var (
+	_ WeightedAggregator = (*StockWeightedAggregator)(nil)
+	_ WeightedAggregator = (*StockWeightedMedianAggregator)(nil)
+)
+
+// StockWeightedAggregator computes the mean of merged prices weighted by
+// their publishers, sums are kept in big integers as price*weight overflows
+type StockWeightedAggregator struct {
+	ticker string
+	sum    *big.Int
+	weight *big.Int
+}
+
+func NewStockWeightedAggregator(name string) *StockWeightedAggregator {
+	res := new(StockWeightedAggregator)
+	res.sum = new(big.Int)
+	res.weight = new(big.Int)
+	// empty at first
+	res.ticker = ""
 
 	return res
 }
 
+func (swa *StockWeightedAggregator) Result(t int64) (Entity, error) {
+	if swa.weight.Sign() == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	res := new(Stock)
+	res.Ticker = swa.ticker
+	res.Price = new(big.Int).Quo(swa.sum, swa.weight).Uint64()
+	res.publisher = crypto.EmptyPublicKey
+	res.tick = t
+
+	return res, nil
+}
+
+func (*StockWeightedAggregator) Measure(e Entity) (uint64, bool) {
+	return measureStock(e)
+}
+
+func (swa *StockWeightedAggregator) MergeOne(s Entity) {
+	swa.MergeWeighted(s, 1)
+}
+
+func (swa *StockWeightedAggregator) RemoveOne(s Entity) {
+	swa.RemoveWeighted(s, 1)
+}
+
+func (swa *StockWeightedAggregator) MergeWeighted(s Entity, weight uint64) {
+	stk, ok := s.(*Stock)
+	if !ok {
+		return
+	}
+
+	if swa.ticker == "" {
+		swa.ticker = stk.Ticker
+	}
+	w := new(big.Int).SetUint64(weight)
+	swa.sum.Add(swa.sum, w.Mul(w, new(big.Int).SetUint64(stk.Price)))
+	swa.weight.Add(swa.weight, new(big.Int).SetUint64(weight))
+}
+
+func (swa *StockWeightedAggregator) RemoveWeighted(s Entity, weight uint64) {
+	stk, ok := s.(*Stock)
+	if !ok {
+		return
+	}
+
+	w := new(big.Int).SetUint64(weight)
+	swa.sum.Sub(swa.sum, w.Mul(w, new(big.Int).SetUint64(stk.Price)))
+	swa.weight.Sub(swa.weight, new(big.Int).SetUint64(weight))
+}
+
+type weightedPrice struct {
+	price  uint64
+	weight uint64
+}
+
+// StockWeightedMedianAggregator keeps merged prices sorted, the result is the
+// lowest price reaching half of the total weight
+type StockWeightedMedianAggregator struct {
+	ticker string
+	prices []weightedPrice
+	total  *big.Int
+}
+
+func NewStockWeightedMedianAggregator(name string) *StockWeightedMedianAggregator {
+	res := new(StockWeightedMedianAggregator)
+	res.prices = make([]weightedPrice, 0)
+	res.total = new(big.Int)
+	// empty at first
+	res.ticker = ""
+
+	return res
+}
+
+func (swma *StockWeightedMedianAggregator) Result(t int64) (Entity, error) {
+	if swma.total.Sign() == 0 {
+		return nil, ErrZeroDenominator
+	}
+
+	res := new(Stock)
+	res.Ticker = swma.ticker
+	res.publisher = crypto.EmptyPublicKey
+	res.tick = t
+
+	// cumulative*2 >= total
+	cumulative := new(big.Int)
+	doubled := new(big.Int)
+	for _, wp := range swma.prices {
+		cumulative.Add(cumulative, new(big.Int).SetUint64(wp.weight))
+		if doubled.Lsh(cumulative, 1).Cmp(swma.total) >= 0 {
+			res.Price = wp.price
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (*StockWeightedMedianAggregator) Measure(e Entity) (uint64, bool) {
+	return measureStock(e)
+}
+
+func (swma *StockWeightedMedianAggregator) MergeOne(s Entity) {
+	swma.MergeWeighted(s, 1)
+}
+
+func (swma *StockWeightedMedianAggregator) RemoveOne(s Entity) {
+	swma.RemoveWeighted(s, 1)
+}
+
+func (swma *StockWeightedMedianAggregator) MergeWeighted(s Entity, weight uint64) {
+	stk, ok := s.(*Stock)
+	if !ok || weight == 0 {
+		return
+	}
+
+	if swma.ticker == "" {
+		swma.ticker = stk.Ticker
+	}
+
+	i := sort.Search(len(swma.prices), func(i int) bool { return swma.prices[i].price >= stk.Price })
+	swma.prices = append(swma.prices, weightedPrice{})
+	copy(swma.prices[i+1:], swma.prices[i:])
+	swma.prices[i] = weightedPrice{stk.Price, weight}
+	swma.total.Add(swma.total, new(big.Int).SetUint64(weight))
+}
+
+func (swma *StockWeightedMedianAggregator) RemoveWeighted(s Entity, weight uint64) {
+	stk, ok := s.(*Stock)
+	if !ok {
+		return
+	}
+
+	i := sort.Search(len(swma.prices), func(i int) bool { return swma.prices[i].price >= stk.Price })
+	for ; i < len(swma.prices) && swma.prices[i].price == stk.Price; i++ {
+		if swma.prices[i].weight == weight {
+			swma.prices = append(swma.prices[:i], swma.prices[i+1:]...)
+			swma.total.Sub(swma.total, new(big.Int).SetUint64(weight))
+			return
+		}
+	}
+}
+
+func (s *Stock) String() string {
+	return fmt.Sprintf("%s %d", s.Ticker, s.Price)
+}
+
+func (s *Stock) Marshal() ([]byte, error) {
+	return encodeEntity(codec.StringLen(s.Ticker)+hconsts.Uint64Len, func(p *codec.Packer) {
+		p.PackString(s.Ticker)
+		p.PackUint64(s.Price)
+	})
+}
+
 func UnmarshalStock(payload []byte) (*Stock, error) {
 	var s Stock
-	err := json.Unmarshal(payload, &s)
+	err := decodeEntity(payload, &s, func(p *codec.Packer) {
+		s.Ticker = p.UnpackString(false)
+		s.Price = p.UnpackUint64(false)
+	})
 
 	if err != nil {
 		return nil, err
@@ -1,6 +1,8 @@
 package oracle_test
 
 import (
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -10,7 +12,7 @@ import (
 
 func TestStockAggregate(t *testing.T) {
 	stockName := "Stock-1"
-	collection := oracle.NewEntityCollection(time.Now().Unix(), 0, 0, stockName)
+	collection := oracle.NewEntityCollection(time.Now().Unix(), 0, 0, oracle.MeanAggregatorID, stockName)
 
 	n := 10
 	entities := make([]oracle.Entity, n)
@@ -25,15 +27,16 @@ func TestStockAggregate(t *testing.T) {
 
 	collection.MergeMany(entities)
 
-	r1, e1 := collection.Result()
+	blockTick := time.Now().UnixMilli()
+	r1, e1 := collection.Result(blockTick)
 
-	if e1 != nil {
+	if e1 != nil || r1.Tick() != blockTick {
 		t.Errorf("error aggregation: %+v, %+v", e1, r1)
 	}
 
 	collection.RemoveMany(5)
 
-	r2, e2 := collection.Result()
+	r2, e2 := collection.Result(blockTick)
 
 	// 6000, ..., 10000
 	priceShouldBe := 8000
@@ -41,6 +44,40 @@ func TestStockAggregate(t *testing.T) {
 	if e2 != nil || priceShouldBe != int(r2.(*oracle.Stock).Price) {
 		t.Errorf("error aggregation: %+v, %+v", e2, r2)
 	}
+
+	// overflowing sum of prices
+	huge := oracle.NewStockAggregator(stockName)
+	huge.MergeOne(oracle.NewStock(stockName, math.MaxUint64, publisher, 0))
+	huge.MergeOne(oracle.NewStock(stockName, math.MaxUint64-2, publisher, 0))
+	r3, e3 := huge.Result(blockTick)
+	if e3 != nil || r3.(*oracle.Stock).Price != math.MaxUint64-1 {
+		t.Errorf("error overflowing mean: %+v, %+v", e3, r3)
+	}
+}
+
+func TestCollectionWindow(t *testing.T) {
+	collection := oracle.NewEntityCollection(0, 0, oracle.StockID, oracle.MeanAggregatorID, "Stock-1")
+	publisher := crypto.EmptyPublicKey
+
+	collection.MergeMany([]oracle.Entity{
+		oracle.NewStock("Stock-1", 1000, publisher, 100),
+		oracle.NewStock("Stock-1", 2000, publisher, 200),
+		oracle.NewStock("Stock-1", 3000, publisher, 300),
+	})
+
+	if collection.MinTick != 100 || collection.MaxTick != 300 {
+		t.Errorf("unexpected collection window: [%d, %d]", collection.MinTick, collection.MaxTick)
+	}
+
+	collection.RemoveMany(1)
+	if collection.MinTick != 200 {
+		t.Errorf("unexpected collection window start: %d", collection.MinTick)
+	}
+
+	collection.Clear()
+	if collection.MinTick != 300 || collection.MaxTick != 300 {
+		t.Errorf("unexpected collection window after clear: [%d, %d]", collection.MinTick, collection.MaxTick)
+	}
 }
 
 func TestStockMarshal(t *testing.T) {
@@ -53,3 +90,153 @@ func TestStockMarshal(t *testing.T) {
 
 	// t.Errorf("%+v", stock)
 }
+
+func TestStockDeviation(t *testing.T) {
+	ref := oracle.NewStock("Stock-1", 1000, crypto.EmptyPublicKey, 0)
+
+	cases := []struct {
+		price     uint64
+		deviation uint64
+	}{
+		{1000, 0},
+		{1100, 1000},
+		{900, 1000},
+		{2000, 10000},
+	}
+	for _, c := range cases {
+		s := oracle.NewStock("Stock-1", c.price, crypto.EmptyPublicKey, 0)
+		if d := s.Deviation(ref); d != c.deviation {
+			t.Errorf("deviation of %d: %d != %d", c.price, d, c.deviation)
+		}
+	}
+
+	zero := oracle.NewStock("Stock-1", 0, crypto.EmptyPublicKey, 0)
+	if d := ref.Deviation(zero); d != math.MaxUint64 {
+		t.Errorf("deviation from zero price: %d", d)
+	}
+}
+
+func TestStockMedianAggregate(t *testing.T) {
+	stockName := "Stock-1"
+	collection := oracle.NewEntityCollection(time.Now().Unix(), 0, oracle.StockID, oracle.MedianAggregatorID, stockName)
+
+	// 5000, 1000, 4000, 2000, 100000
+	prices := []uint64{5000, 1000, 4000, 2000, 100000}
+	entities := make([]oracle.Entity, len(prices))
+	for i, price := range prices {
+		entities[i] = oracle.NewStock(stockName, price, crypto.EmptyPublicKey, time.Now().Unix())
+	}
+	collection.MergeMany(entities)
+
+	blockTick := time.Now().UnixMilli()
+	r1, e1 := collection.Result(blockTick)
+	// outlier does not move the median
+	if e1 != nil || r1.(*oracle.Stock).Price != 4000 {
+		t.Errorf("error aggregation: %+v, %+v", e1, r1)
+	}
+
+	// 4000, 2000, 100000 left
+	collection.RemoveMany(2)
+	r2, e2 := collection.Result(blockTick)
+	if e2 != nil || r2.(*oracle.Stock).Price != 4000 {
+		t.Errorf("error aggregation: %+v, %+v", e2, r2)
+	}
+
+	// even count takes the mean of the two middle prices
+	collection.RemoveMany(1)
+	r3, e3 := collection.Result(blockTick)
+	if e3 != nil || r3.(*oracle.Stock).Price != 51000 {
+		t.Errorf("error aggregation: %+v, %+v", e3, r3)
+	}
+
+	collection.RemoveMany(2)
+	if _, err := collection.Result(blockTick); err == nil {
+		t.Errorf("empty median aggregation should fail")
+	}
+}
+
+func TestStockWeightedAggregate(t *testing.T) {
+	stockName := "Stock-1"
+	prices := []uint64{1000, 2000, 9000}
+	weights := []uint64{1, 1, 8}
+
+	entities := make([]oracle.Entity, len(prices))
+	for i, price := range prices {
+		entities[i] = oracle.NewStock(stockName, price, crypto.EmptyPublicKey, 0)
+	}
+
+	// (1000 + 2000 + 9000*8) / 10
+	mean, _, err := oracle.Aggregate(oracle.StockID, oracle.WeightedMeanAggregatorID, nil, new(oracle.OutlierFilter), 0, entities, weights)
+	if err != nil || mean.(*oracle.Stock).Price != 7500 {
+		t.Errorf("error weighted mean: %+v, %+v", err, mean)
+	}
+
+	median, _, err := oracle.Aggregate(oracle.StockID, oracle.WeightedMedianAggregatorID, nil, new(oracle.OutlierFilter), 0, entities, weights)
+	if err != nil || median.(*oracle.Stock).Price != 9000 {
+		t.Errorf("error weighted median: %+v, %+v", err, median)
+	}
+
+	// equal weights without weights given
+	median, _, err = oracle.Aggregate(oracle.StockID, oracle.WeightedMedianAggregatorID, nil, new(oracle.OutlierFilter), 0, entities, nil)
+	if err != nil || median.(*oracle.Stock).Price != 2000 {
+		t.Errorf("error unweighted median: %+v, %+v", err, median)
+	}
+
+	if _, _, err := oracle.Aggregate(oracle.StockID, oracle.WeightedMeanAggregatorID, nil, new(oracle.OutlierFilter), 0, entities, weights[:1]); err != oracle.ErrWeightsMismatch {
+		t.Errorf("weights mismatch should fail: %+v", err)
+	}
+
+	// overflowing price*weight
+	huge := []uint64{math.MaxUint64, math.MaxUint64, math.MaxUint64}
+	mean, _, err = oracle.Aggregate(oracle.StockID, oracle.WeightedMeanAggregatorID, nil, new(oracle.OutlierFilter), 0, entities, huge)
+	if err != nil || mean.(*oracle.Stock).Price != 4000 {
+		t.Errorf("error overflowing weighted mean: %+v, %+v", err, mean)
+	}
+}
+
+func TestStockWeightedMedianRemove(t *testing.T) {
+	aggregator := oracle.NewStockWeightedMedianAggregator("Stock-1")
+	a := oracle.NewStock("Stock-1", 1000, crypto.EmptyPublicKey, 0)
+	b := oracle.NewStock("Stock-1", 3000, crypto.EmptyPublicKey, 0)
+
+	aggregator.MergeWeighted(a, 1)
+	aggregator.MergeWeighted(b, 5)
+	aggregator.RemoveWeighted(b, 5)
+
+	res, err := aggregator.Result(0)
+	if err != nil || res.(*oracle.Stock).Price != 1000 {
+		t.Errorf("error weighted median: %+v, %+v", err, res)
+	}
+}
+
+func TestStockValidation(t *testing.T) {
+	for _, tc := range []struct {
+		stock *oracle.Stock
+		field string
+	}{
+		{oracle.NewStock("", 1000, crypto.EmptyPublicKey, 0), "ticker"},
+		{oracle.NewStock("AMD", 0, crypto.EmptyPublicKey, 0), "price"},
+		{oracle.NewStock("AMD", 1000, crypto.EmptyPublicKey, 0), ""},
+		{oracle.NewStock("AMD", 10000, crypto.EmptyPublicKey, 0), ""},
+	} {
+		_, err := oracle.UnmarshalEntity(oracle.StockID, marshal(t, tc.stock))
+		var fe *oracle.FieldError
+		if tc.field == "" && err != nil {
+			t.Errorf("unexpected rejection of %s: %+v", tc.stock, err)
+		} else if tc.field != "" && (!errors.As(err, &fe) || fe.Field != tc.field) {
+			t.Errorf("expected %s to be rejected on %s, got %+v", tc.stock, tc.field, err)
+		}
+	}
+
+	meta := &oracle.EntityCollectionMeta{EntityName: "AMD", EntityType: oracle.StockID}
+	if err := meta.Accepts(oracle.NewStock("AMD", 1000, crypto.EmptyPublicKey, 0)); err != nil {
+		t.Errorf("unexpected rejection: %+v", err)
+	}
+	err := meta.Accepts(oracle.NewStock("INTC", 1000, crypto.EmptyPublicKey, 0))
+	if !errors.Is(err, oracle.ErrNameMismatch) {
+		t.Errorf("expected name mismatch, got %+v", err)
+	}
+	if string(err.(*oracle.FieldError).Marshal()) != `{"field":"ticker","error":"`+oracle.ErrNameMismatch.Error()+`"}` {
+		t.Errorf("unexpected output %s", err.(*oracle.FieldError).Marshal())
+	}
+}
@@ -0,0 +1,78 @@
+package oracle
+
+import "math/big"
+
+// Observation is the value of an aggregation result produced at block
+// timestamp [Tick], observations are kept in state to compute time-weighted
+// averages
+type Observation struct {
+	Tick  int64
+	Value uint64
+}
+
+// Observe returns the value of an aggregation result recorded as
+// [Observation], entities that can't be measured are never observed
+func Observe(_type uint64, e Entity) (uint64, bool) {
+	et, ok := LookupEntityType(_type)
+	if !ok || et.Measure == nil {
+		return 0, false
+	}
+
+	return et.Measure(e)
+}
+
+// TWAP returns the average of [observations] weighted by the time each of them
+// held within the window of [window] milliseconds ending at [t]. An observation
+// holds until the next one, the latest holds until [t]. Windows reaching
+// before the oldest observation only average the time covered.
+func TWAP(observations []Observation, t int64, window int64) (uint64, error) {
+	if window <= 0 {
+		return 0, ErrInvalidWindow
+	}
+
+	start := t - window
+	sum := new(big.Int)
+	var covered int64
+	for i, o := range observations {
+		if o.Tick > t {
+			break
+		}
+		end := t
+		if i+1 < len(observations) && observations[i+1].Tick < t {
+			end = observations[i+1].Tick
+		}
+		from := o.Tick
+		if from < start {
+			from = start
+		}
+		if end <= from {
+			continue
+		}
+
+		elapsed := end - from
+		sum.Add(sum, new(big.Int).Mul(new(big.Int).SetUint64(o.Value), big.NewInt(elapsed)))
+		covered += elapsed
+	}
+
+	if covered == 0 {
+		return 0, ErrNoObservations
+	}
+
+	return sum.Div(sum, big.NewInt(covered)).Uint64(), nil
+}
+
+// TimeWeighted returns a copy of the [latest] aggregation result carrying the
+// time-weighted average of [observations] over [window], stamped with [t]
+func TimeWeighted(_type uint64, latest Entity, observations []Observation, t int64, window int64) (Entity, error) {
+	value, err := TWAP(observations, t, window)
+	if err != nil {
+		return nil, err
+	}
+
+	et, ok := LookupEntityType(_type)
+	if !ok || et.WithValue == nil {
+		return nil, ErrNotSupportedEntity
+	}
+
+	return et.WithValue(latest, value, t)
+}
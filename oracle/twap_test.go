@@ -0,0 +1,59 @@
+package oracle_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/hypersdk/crypto"
+	"github.com/bianyuanop/oraclevm/oracle"
+)
+
+func TestTWAP(t *testing.T) {
+	observations := []oracle.Observation{
+		{Tick: 0, Value: 100},
+		{Tick: 10, Value: 200},
+		{Tick: 20, Value: 400},
+	}
+
+	cases := []struct {
+		t      int64
+		window int64
+		want   uint64
+	}{
+		{30, 30, 233},
+		{30, 15, 333},
+		// only the covered time is averaged
+		{30, 100, 233},
+		// later observations are ignored
+		{5, 10, 100},
+	}
+	for _, c := range cases {
+		got, err := oracle.TWAP(observations, c.t, c.window)
+		if err != nil || got != c.want {
+			t.Errorf("twap at %d over %d: expected %d, got %d, %+v", c.t, c.window, c.want, got, err)
+		}
+	}
+
+	if _, err := oracle.TWAP(observations, 30, 0); err != oracle.ErrInvalidWindow {
+		t.Errorf("empty window should fail: %+v", err)
+	}
+	if _, err := oracle.TWAP(nil, 30, 10); err != oracle.ErrNoObservations {
+		t.Errorf("missing observations should fail: %+v", err)
+	}
+}
+
+func TestTimeWeighted(t *testing.T) {
+	latest := oracle.NewStock("AMD", 400, crypto.EmptyPublicKey, 20)
+	observations := []oracle.Observation{
+		{Tick: 0, Value: 100},
+		{Tick: 20, Value: 400},
+	}
+
+	e, err := oracle.TimeWeighted(oracle.StockID, latest, observations, 40, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stk := e.(*oracle.Stock)
+	if stk.Ticker != "AMD" || stk.Price != 250 || stk.Tick() != 40 {
+		t.Errorf("unexpected time-weighted stock: %+v", stk)
+	}
+}
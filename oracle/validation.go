@@ -0,0 +1,49 @@
+package oracle
+
+import (
+	"encoding/json"
+
+	"github.com/bianyuanop/oraclevm/consts"
+)
+
+// FieldError reports the field of an uploaded entity failing validation,
+// [Err] is the cause
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func NewFieldError(field string, err error) *FieldError {
+	return &FieldError{Field: field, Err: err}
+}
+
+func (fe *FieldError) Error() string {
+	return fe.Field + ": " + fe.Err.Error()
+}
+
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// Marshal encodes [fe] as the output of rejected uploads, e.g.
+// `{"field":"price","error":"Value must be positive"}`
+func (fe *FieldError) Marshal() []byte {
+	// should always success
+	res, _ := json.Marshal(struct {
+		Field string `json:"field"`
+		Error string `json:"error"`
+	}{fe.Field, fe.Err.Error()})
+
+	return res
+}
+
+// verifyText checks the text [field] is set and bounded
+func verifyText(field string, value string) error {
+	if len(value) == 0 {
+		return NewFieldError(field, ErrRequiredField)
+	}
+	if len(value) > consts.EntityFieldMaxLen {
+		return NewFieldError(field, ErrFieldTooLong)
+	}
+	return nil
+}
@@ -25,6 +25,15 @@ func init() {
 		consts.ActionRegistry.Register((&actions.Transfer{}).GetTypeID(), actions.UnmarshalTransfer, false),
 		consts.ActionRegistry.Register((&actions.UploadEntity{}).GetTypeID(), actions.UnmarshalUploadEntity, false),
 		consts.ActionRegistry.Register((&actions.Query{}).GetTypeID(), actions.UnmarshalQuery, true),
+		consts.ActionRegistry.Register((&actions.Aggregate{}).GetTypeID(), actions.UnmarshalAggregate, false),
+		consts.ActionRegistry.Register((&actions.RegisterEntity{}).GetTypeID(), actions.UnmarshalRegisterEntity, false),
+		consts.ActionRegistry.Register((&actions.UpdateFeeder{}).GetTypeID(), actions.UnmarshalUpdateFeeder, false),
+		consts.ActionRegistry.Register((&actions.Stake{}).GetTypeID(), actions.UnmarshalStake, false),
+		consts.ActionRegistry.Register((&actions.Unstake{}).GetTypeID(), actions.UnmarshalUnstake, false),
+		consts.ActionRegistry.Register((&actions.BatchQuery{}).GetTypeID(), actions.UnmarshalBatchQuery, true),
+		consts.ActionRegistry.Register((&actions.FundQueries{}).GetTypeID(), actions.UnmarshalFundQueries, false),
+		consts.ActionRegistry.Register((&actions.Subscribe{}).GetTypeID(), actions.UnmarshalSubscribe, true),
+		consts.ActionRegistry.Register((&actions.SubmitReport{}).GetTypeID(), actions.UnmarshalSubmitReport, false),
 
 		// When registering new auth, ALWAYS make sure to append at the end.
 		consts.AuthRegistry.Register((&auth.ED25519{}).GetTypeID(), auth.UnmarshalED25519, false),
@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/bianyuanop/oraclevm/genesis"
 	"github.com/bianyuanop/oraclevm/oracle"
+	"github.com/bianyuanop/oraclevm/storage"
 )
 
 type Controller interface {
@@ -21,4 +22,14 @@ type Controller interface {
 	GetHistoryFromState(uint64, uint64) ([]oracle.Entity, error)
 	GetAvailableEntities() ([]*oracle.EntityCollectionMeta, error)
 	GetEntitiesCollectionCount(entityIndex uint64) (uint64, error)
+	GetFeedersFromState(context.Context, uint64) ([]crypto.PublicKey, error)
+	GetStakeFromState(context.Context, crypto.PublicKey) (uint64, uint64, error)
+	GetEntityRoundFromState(context.Context, uint64) (*storage.EntityRound, error)
+	GetRewardFromState(context.Context, crypto.PublicKey) (uint64, uint64, error)
+	GetRewardPoolFromState(context.Context) (uint64, error)
+	GetQueryBalanceFromState(context.Context, ids.ID) (uint64, error)
+	GetSubscriptionsFromState(context.Context, uint64) ([]*storage.Subscription, error)
+	GetTWAPFromState(context.Context, uint64, int64, int64) (uint64, oracle.Entity, error)
+	LastAcceptedTimestamp() int64
+	GetRoundAtFromState(context.Context, uint64, int64) (bool, uint64, error)
 }
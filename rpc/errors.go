@@ -6,3 +6,4 @@ package rpc
 import "errors"
 
 var ErrTxNotFound = errors.New("tx not found")
+var ErrNoRoundClosed = errors.New("no round closed at tick")
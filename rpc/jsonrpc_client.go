@@ -130,6 +130,145 @@ func (cli *JSONRPCClient) CollectionCount(ctx context.Context, entityIndex uint6
 	return resp.Count, err
 }
 
+func (cli *JSONRPCClient) Feeders(ctx context.Context, entityIndex uint64) ([]string, error) {
+	resp := new(FeedersReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"feeders",
+		&FeedersArgs{
+			EntityIndex: entityIndex,
+		},
+		resp,
+	)
+
+	return resp.Feeders, err
+}
+
+func (cli *JSONRPCClient) Stake(ctx context.Context, addr string) (uint64, uint64, error) {
+	resp := new(StakeReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"stake",
+		&StakeArgs{
+			Address: addr,
+		},
+		resp,
+	)
+
+	return resp.Amount, resp.Pending, err
+}
+
+func (cli *JSONRPCClient) Round(ctx context.Context, entityIndex uint64) (*RoundReply, error) {
+	resp := new(RoundReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"round",
+		&RoundArgs{
+			EntityIndex: entityIndex,
+		},
+		resp,
+	)
+
+	return resp, err
+}
+
+func (cli *JSONRPCClient) Rewards(ctx context.Context, addr string) (uint64, uint64, error) {
+	resp := new(RewardsReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"rewards",
+		&RewardsArgs{
+			Address: addr,
+		},
+		resp,
+	)
+
+	return resp.Earned, resp.Rounds, err
+}
+
+func (cli *JSONRPCClient) RewardPool(ctx context.Context) (uint64, error) {
+	resp := new(RewardPoolReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"rewardPool",
+		nil,
+		resp,
+	)
+
+	return resp.Amount, err
+}
+
+// QueryBalance returns query fees prepaid for [chainID]
+func (cli *JSONRPCClient) QueryBalance(ctx context.Context, chainID ids.ID) (uint64, error) {
+	resp := new(QueryBalanceReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"queryBalance",
+		&QueryBalanceArgs{
+			ChainID: chainID,
+		},
+		resp,
+	)
+
+	return resp.Amount, err
+}
+
+func (cli *JSONRPCClient) Subscriptions(ctx context.Context, entityIndex uint64) ([]*Subscription, error) {
+	resp := new(SubscriptionsReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"subscriptions",
+		&SubscriptionsArgs{
+			EntityIndex: entityIndex,
+		},
+		resp,
+	)
+
+	return resp.Subscriptions, err
+}
+
+// Twap returns the time-weighted average of aggregation results of
+// [entityIndex] over [window] milliseconds ending at [tick], 0 ends it now
+func (cli *JSONRPCClient) Twap(ctx context.Context, entityIndex uint64, window int64, tick int64) (*TwapReply, error) {
+	resp := new(TwapReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"twap",
+		&TwapArgs{
+			EntityIndex: entityIndex,
+			Window:      window,
+			Tick:        tick,
+		},
+		resp,
+	)
+
+	return resp, err
+}
+
+func (cli *JSONRPCClient) RoundAt(ctx context.Context, entityIndex uint64, tick int64) (uint64, error) {
+	resp := new(RoundAtReply)
+
+	err := cli.requester.SendRequest(
+		ctx,
+		"roundAt",
+		&RoundAtArgs{
+			EntityIndex: entityIndex,
+			Tick:        tick,
+		},
+		resp,
+	)
+
+	return resp.Round, err
+}
+
 func (cli *JSONRPCClient) WaitForBalance(
 	ctx context.Context,
 	addr string,
@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/hypersdk/crypto"
 
 	"github.com/bianyuanop/oraclevm/genesis"
 	"github.com/bianyuanop/oraclevm/oracle"
@@ -89,6 +91,8 @@ type HistoryArgs struct {
 type HistoryReply struct {
 	History [][]byte `json:"history"`
 	Length  int      `json:"length"`
+	// rendered results, e.g. scaled values of numeric feeds
+	Values []string `json:"values,omitempty"`
 }
 
 func (j *JSONRPCServer) History(req *http.Request, args *HistoryArgs, reply *HistoryReply) error {
@@ -103,7 +107,13 @@ func (j *JSONRPCServer) History(req *http.Request, args *HistoryArgs, reply *His
 	reply.Length = len(history)
 	reply.History = make([][]byte, reply.Length)
 	for i := 0; i < reply.Length; i++ {
-		reply.History[i] = history[i].Marshal()
+		reply.History[i], err = history[i].Marshal()
+		if err != nil {
+			return err
+		}
+		if value := oracle.Render(history[i]); len(value) > 0 {
+			reply.Values = append(reply.Values, value)
+		}
 	}
 
 	return nil
@@ -153,3 +163,267 @@ func (j *JSONRPCServer) Eccount(req *http.Request, args *EntityCollectionCountAr
 
 	return nil
 }
+
+type FeedersArgs struct {
+	EntityIndex uint64 `json:"index"`
+}
+
+type FeedersReply struct {
+	Feeders []string `json:"feeders"`
+}
+
+// Feeders returns publishers authorized to upload to an entity collection
+func (j *JSONRPCServer) Feeders(req *http.Request, args *FeedersArgs, reply *FeedersReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Feeders")
+	defer span.End()
+
+	feeders, err := j.c.GetFeedersFromState(ctx, args.EntityIndex)
+	if err != nil {
+		return err
+	}
+
+	reply.Feeders = make([]string, len(feeders))
+	for i, feeder := range feeders {
+		reply.Feeders[i] = utils.Address(feeder)
+	}
+
+	return nil
+}
+
+type StakeArgs struct {
+	Address string `json:"address"`
+}
+
+type StakeReply struct {
+	Amount  uint64 `json:"amount"`
+	Pending uint64 `json:"pending"`
+}
+
+func (j *JSONRPCServer) Stake(req *http.Request, args *StakeArgs, reply *StakeReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Stake")
+	defer span.End()
+
+	addr, err := utils.ParseAddress(args.Address)
+	if err != nil {
+		return err
+	}
+	amount, pending, err := j.c.GetStakeFromState(ctx, addr)
+	if err != nil {
+		return err
+	}
+	reply.Amount = amount
+	reply.Pending = pending
+	return nil
+}
+
+type RoundArgs struct {
+	EntityIndex uint64 `json:"index"`
+}
+
+type RoundReply struct {
+	Round       uint64   `json:"round"`
+	StartTick   int64    `json:"startTick"`
+	Submissions int      `json:"submissions"`
+	Publishers  []string `json:"publishers"`
+}
+
+// Round returns the current aggregation round of an entity collection,
+// [Publishers] are deduplicated and required by the `Aggregate` action
+func (j *JSONRPCServer) Round(req *http.Request, args *RoundArgs, reply *RoundReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Round")
+	defer span.End()
+
+	round, err := j.c.GetEntityRoundFromState(ctx, args.EntityIndex)
+	if err != nil {
+		return err
+	}
+
+	reply.Round = round.Round
+	reply.StartTick = round.StartTick
+	reply.Submissions = len(round.Submissions)
+	reply.Publishers = []string{}
+	seen := set.NewSet[crypto.PublicKey](len(round.Submissions))
+	for _, s := range round.Submissions {
+		if seen.Contains(s.Publisher) {
+			continue
+		}
+		seen.Add(s.Publisher)
+		reply.Publishers = append(reply.Publishers, utils.Address(s.Publisher))
+	}
+
+	return nil
+}
+
+type RewardsArgs struct {
+	Address string `json:"address"`
+}
+
+type RewardsReply struct {
+	Earned uint64 `json:"earned"`
+	Rounds uint64 `json:"rounds"`
+}
+
+// Rewards returns the total reward earned by a publisher and the number of
+// rounds it was rewarded in
+func (j *JSONRPCServer) Rewards(req *http.Request, args *RewardsArgs, reply *RewardsReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Rewards")
+	defer span.End()
+
+	addr, err := utils.ParseAddress(args.Address)
+	if err != nil {
+		return err
+	}
+	earned, rounds, err := j.c.GetRewardFromState(ctx, addr)
+	if err != nil {
+		return err
+	}
+	reply.Earned = earned
+	reply.Rounds = rounds
+	return nil
+}
+
+type RewardPoolReply struct {
+	Amount uint64 `json:"amount"`
+}
+
+func (j *JSONRPCServer) RewardPool(req *http.Request, _ *struct{}, reply *RewardPoolReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.RewardPool")
+	defer span.End()
+
+	amount, err := j.c.GetRewardPoolFromState(ctx)
+	if err != nil {
+		return err
+	}
+	reply.Amount = amount
+	return nil
+}
+
+type QueryBalanceArgs struct {
+	ChainID ids.ID `json:"chainID"`
+}
+
+type QueryBalanceReply struct {
+	Amount uint64 `json:"amount"`
+}
+
+// QueryBalance returns query fees prepaid for a querying chain
+func (j *JSONRPCServer) QueryBalance(req *http.Request, args *QueryBalanceArgs, reply *QueryBalanceReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.QueryBalance")
+	defer span.End()
+
+	amount, err := j.c.GetQueryBalanceFromState(ctx, args.ChainID)
+	if err != nil {
+		return err
+	}
+	reply.Amount = amount
+	return nil
+}
+
+type SubscriptionsArgs struct {
+	EntityIndex uint64 `json:"index"`
+}
+
+type Subscription struct {
+	DestinationChainID ids.ID `json:"destinationChainID"`
+	Deviation          uint64 `json:"deviation"`
+	Heartbeat          int64  `json:"heartbeat"`
+	Tick               int64  `json:"tick"`
+	LastValue          uint64 `json:"lastValue"`
+	LastTick           int64  `json:"lastTick"`
+}
+
+type SubscriptionsReply struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+}
+
+// Subscriptions returns chains subscribed to pushes of an entity collection
+func (j *JSONRPCServer) Subscriptions(req *http.Request, args *SubscriptionsArgs, reply *SubscriptionsReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Subscriptions")
+	defer span.End()
+
+	subs, err := j.c.GetSubscriptionsFromState(ctx, args.EntityIndex)
+	if err != nil {
+		return err
+	}
+	reply.Subscriptions = make([]*Subscription, len(subs))
+	for i, sub := range subs {
+		reply.Subscriptions[i] = &Subscription{
+			DestinationChainID: sub.DestinationChainID,
+			Deviation:          sub.Deviation,
+			Heartbeat:          sub.Heartbeat,
+			Tick:               sub.Tick,
+			LastValue:          sub.LastValue,
+			LastTick:           sub.LastTick,
+		}
+	}
+	return nil
+}
+
+type TwapArgs struct {
+	EntityIndex uint64 `json:"index"`
+	// time window in milliseconds
+	Window int64 `json:"window"`
+	// end of the window, defaults to the timestamp of the last accepted block
+	Tick int64 `json:"tick"`
+}
+
+type TwapReply struct {
+	EntityType uint64 `json:"entityType"`
+	// payload is `Entity.Marshal()` of the latest aggregation result carrying
+	// the time-weighted average
+	Payload []byte `json:"payload"`
+	Tick    int64  `json:"tick"`
+	// rendered average, e.g. the scaled value of numeric feeds
+	Value string `json:"value,omitempty"`
+}
+
+// Twap returns the time-weighted average of aggregation results observed in
+// state, the same value served by `Query` in TWAP mode
+func (j *JSONRPCServer) Twap(req *http.Request, args *TwapArgs, reply *TwapReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.Twap")
+	defer span.End()
+
+	tick := args.Tick
+	if tick == 0 {
+		tick = j.c.LastAcceptedTimestamp()
+	}
+	entityType, twap, err := j.c.GetTWAPFromState(ctx, args.EntityIndex, args.Window, tick)
+	if err != nil {
+		return err
+	}
+	reply.EntityType = entityType
+	reply.Payload, err = twap.Marshal()
+	if err != nil {
+		return err
+	}
+	reply.Tick = tick
+	reply.Value = oracle.Render(twap)
+	return nil
+}
+
+type RoundAtArgs struct {
+	EntityIndex uint64 `json:"index"`
+	Tick        int64  `json:"tick"`
+}
+
+type RoundAtReply struct {
+	Round uint64 `json:"round"`
+}
+
+// RoundAt returns the latest round of an entity collection closed no later
+// than [Tick], relayers attach it to `Query` transactions answering time
+// queries
+func (j *JSONRPCServer) RoundAt(req *http.Request, args *RoundAtArgs, reply *RoundAtReply) error {
+	ctx, span := j.c.Tracer().Start(req.Context(), "Server.RoundAt")
+	defer span.End()
+
+	found, round, err := j.c.GetRoundAtFromState(ctx, args.EntityIndex, args.Tick)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNoRoundClosed
+	}
+	reply.Round = round
+	return nil
+}
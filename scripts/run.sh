@@ -100,11 +100,18 @@ cat <<EOF > ${TMPDIR}/allocations.json
 [{"address":"morpheus1rvzhmceq997zntgvravfagsks6w0ryud3rylh4cdvayry0dl97nsp30ucp", "balance":1000000000000}]
 EOF
 
+echo "creating entities file"
+cat <<EOF > ${TMPDIR}/entities.json
+[{"name":"AMD", "type":0, "aggregator":0, "feeders":["morpheus1rvzhmceq997zntgvravfagsks6w0ryud3rylh4cdvayry0dl97nsp30ucp"]}, {"name":"Apple", "type":0, "aggregator":0, "feeders":["morpheus1rvzhmceq997zntgvravfagsks6w0ryud3rylh4cdvayry0dl97nsp30ucp"]}]
+EOF
+
 GENESIS_PATH=$2
 if [[ -z "${GENESIS_PATH}" ]]; then
   echo "creating VM genesis file with allocations"
   rm -f ${TMPDIR}/morpheusvm.genesis
   ${TMPDIR}/morpheus-cli genesis generate ${TMPDIR}/allocations.json \
+  --entities-file ${TMPDIR}/entities.json \
+  --admin morpheus1rvzhmceq997zntgvravfagsks6w0ryud3rylh4cdvayry0dl97nsp30ucp \
   --max-block-units 4000000 \
   --window-target-units 100000000000 \
   --min-block-gap ${MIN_BLOCK_GAP} \
@@ -133,8 +140,7 @@ cat <<EOF > ${TMPDIR}/morpheusvm.config
   "streamingBacklogSize": 10000000,
   "continuousProfilerDir":"${TMPDIR}/morpheusvm-e2e-profiles/*",
   "logLevel": "${LOGLEVEL}",
-  "stateSyncServerDelay": ${STATESYNC_DELAY},
-  "trackedStocks": ["AMD", "Apple"]
+  "stateSyncServerDelay": ${STATESYNC_DELAY}
 }
 EOF
 mkdir -p ${TMPDIR}/morpheusvm-e2e-profiles
@@ -14,9 +14,12 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	smath "github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/hypersdk/chain"
+	"github.com/ava-labs/hypersdk/codec"
 	"github.com/ava-labs/hypersdk/consts"
 	"github.com/ava-labs/hypersdk/crypto"
 
+	oconsts "github.com/bianyuanop/oraclevm/consts"
+	"github.com/bianyuanop/oraclevm/oracle"
 	"github.com/bianyuanop/oraclevm/utils"
 )
 
@@ -25,6 +28,10 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 // Metadata
 // 0x0/ (tx)
 //   -> [txID] => timestamp
+// 0x4/ (aggregation result)
+//   -> [tick|entityIndex] => entityIndex|entityType|tick|publisher|payload
+// 0x7/ (entity registration)
+//   -> [entityIndex] => entity collection meta
 //
 // State
 // / (height) => store in root
@@ -33,6 +40,33 @@ type ReadState func(context.Context, [][]byte) ([][]byte, []error)
 //   -> [owner] => balance
 // 0x1/ (hypersdk-incoming warp)
 // 0x2/ (hypersdk-outgoing warp)
+// 0x3/ (entity)
+//   -> [txID] => entityIndex|entityType|tick|publisher|payload
+// 0x5/ (aggregation cache)
+//   -> [entityIndex] => entityType|tick|round|contributors|stale|payload
+// 0x6/ (aggregation round)
+//   -> [entityIndex] => round|entityType|startTick|submissions
+// 0x7/ (entity registry)
+//   -> [entityIndex] => entity collection meta
+// 0x8/ (entity counter) => number of registered entities
+// 0x9/ (admin) => admin public key
+// 0xa/ (entity feeders)
+//   -> [entityIndex] => authorized publishers
+// 0xb/ (stake)
+//   -> [publisher] => amount|pending submissions
+// 0xc/ (reward pool) => amount
+// 0xd/ (reward)
+//   -> [publisher] => earned|rounds
+// 0xe/ (observations)
+//   -> [entityIndex] => recent aggregation results as tick|value
+// 0xf/ (round slot)
+//   -> [entityIndex|publisher] => round|position of the latest submission
+// 0x10/ (aggregation history)
+//   -> [entityIndex|round] => closed tick|aggregation cache valid once the round closed
+// 0x11/ (query balance)
+//   -> [chainID] => prepaid query fees
+// 0x12/ (subscriptions)
+//   -> [entityIndex] => subscribed chains with their thresholds and last push
 
 const (
 	txPrefix = 0x0
@@ -46,6 +80,29 @@ const (
 	// store entity aggregation result
 	entityAggregationResultPrefix = 0x4
 	entityAggregationCachePrefix  = 0x5
+	// store entities submitted in the current aggregation round
+	entityRoundPrefix = 0x6
+	// store registered entity collections
+	entityMetaPrefix    = 0x7
+	entityCounterPrefix = 0x8
+	// store the admin and publishers authorized to upload entities
+	adminPrefix         = 0x9
+	entityFeedersPrefix = 0xa
+	// store publisher stake
+	stakePrefix = 0xb
+	// store rewards distributed to publishers
+	rewardPoolPrefix = 0xc
+	rewardPrefix     = 0xd
+	// store recent aggregation results for time-weighted averages
+	observationsPrefix = 0xe
+	// store the submission slot of publishers in aggregation rounds
+	roundSlotPrefix = 0xf
+	// store the aggregation result of every closed round for point-in-time queries
+	aggregationHistoryPrefix = 0x10
+	// store query fees prepaid for querying chains
+	queryBalancePrefix = 0x11
+	// store chains subscribed to pushes of aggregation results
+	subscriptionsPrefix = 0x12
 )
 
 var (
@@ -289,7 +346,7 @@ func UnpackEntity(v []byte) (
 	tick = int64(binary.BigEndian.Uint64(v[consts.Uint64Len*2:]))
 	publisher = crypto.PublicKey(v[consts.Uint64Len*3:])
 
-	payload = make([]byte, len(v)-consts.Uint64Len*3+crypto.PublicKeyLen)
+	payload = make([]byte, len(v)-(consts.Uint64Len*3+crypto.PublicKeyLen))
 
 	copy(payload, v[consts.Uint64Len*3+crypto.PublicKeyLen:])
 
@@ -385,6 +442,25 @@ func GetAggregationResult(
 	return
 }
 
+// IterateAggregationResults calls [f] with every persisted aggregation result
+// in tick order
+func IterateAggregationResults(
+	db database.Iteratee,
+	f func(entityIndex uint64, entityType uint64, tick int64, payload []byte) error,
+) error {
+	iter := db.NewIteratorWithPrefix([]byte{entityAggregationResultPrefix})
+	defer iter.Release()
+
+	for iter.Next() {
+		entityIndex, entityType, tick, _, payload := UnpackEntity(iter.Value())
+		if err := f(entityIndex, entityType, tick, payload); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
+
 func PrefixAggregationCacheResult(entityIndex uint64) (k []byte) {
 	k = make([]byte, 1+consts.Uint64Len)
 	k[0] = entityAggregationCachePrefix
@@ -393,33 +469,1069 @@ func PrefixAggregationCacheResult(entityIndex uint64) (k []byte) {
 	return
 }
 
+// AggregationCache is the latest aggregation result of an entity collection
+type AggregationCache struct {
+	EntityType uint64
+	// block timestamp the result is aggregated at
+	Tick int64
+	// aggregation round the result is produced by
+	Round uint64
+	// number of distinct publishers the result is aggregated from
+	Contributors uint64
+	// number of submissions of the round rejected as outliers
+	Rejected uint64
+	// set when a later round closed without reaching quorum, the result is
+	// retained from an earlier round
+	Stale bool
+	// payload is `Entity.Marshal()`
+	Payload []byte
+}
+
+func PackAggregationCache(ac *AggregationCache) ([]byte, error) {
+	p := codec.NewWriter(consts.Uint64Len*4+consts.Int64Len+consts.BoolLen+codec.BytesLen(ac.Payload), consts.MaxInt)
+
+	p.PackUint64(ac.EntityType)
+	p.PackInt64(ac.Tick)
+	p.PackUint64(ac.Round)
+	p.PackUint64(ac.Contributors)
+	p.PackUint64(ac.Rejected)
+	p.PackBool(ac.Stale)
+	p.PackBytes(ac.Payload)
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackAggregationCache(v []byte) (*AggregationCache, error) {
+	p := codec.NewReader(v, consts.MaxInt)
+
+	ac := new(AggregationCache)
+	ac.EntityType = p.UnpackUint64(false)
+	ac.Tick = p.UnpackInt64(false)
+	ac.Round = p.UnpackUint64(false)
+	ac.Contributors = p.UnpackUint64(false)
+	ac.Rejected = p.UnpackUint64(false)
+	ac.Stale = p.UnpackBool()
+	p.UnpackBytes(consts.MaxInt, true, &ac.Payload)
+
+	return ac, p.Err()
+}
+
 func CacheAggregationResult(
 	ctx context.Context,
-	db database.KeyValueWriter,
-	entityType uint64,
+	db chain.Database,
 	entityIndex uint64,
-	tick int64,
-	payload []byte,
+	ac *AggregationCache,
 ) error {
 	k := PrefixAggregationCacheResult(entityIndex)
-	v := PackEntity(entityIndex, entityType, tick, crypto.EmptyPublicKey, payload)
+	v, err := PackAggregationCache(ac)
+	if err != nil {
+		return err
+	}
 
-	return db.Put(k, v)
+	return db.Insert(ctx, k, v)
 }
 
+// GetCachedAggregationResult returns the latest aggregation result of
+// [entityIndex], [database.ErrNotFound] if it is never aggregated
 func GetCachedAggregationResult(
 	ctx context.Context,
 	db chain.Database,
 	entityIndex uint64,
-) (entityType uint64, payload []byte, e error) {
+) (*AggregationCache, error) {
 	k := PrefixAggregationCacheResult(entityIndex)
 
 	v, err := db.GetValue(ctx, k)
 	if err != nil {
-		return 0, make([]byte, 0), err
+		return nil, err
 	}
 
-	_, entityType, _, _, payload = UnpackEntity(v)
+	return UnpackAggregationCache(v)
+}
+
+// [entityRoundPrefix] + [entityIndex]
+func PrefixEntityRoundKey(entityIndex uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = entityRoundPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+
+	return
+}
+
+type RoundSubmission struct {
+	Publisher crypto.PublicKey
+	Tick      int64
+	Payload   []byte
+}
+
+// EntityRound holds the entities submitted to an entity collection since the
+// last `Aggregate` action, [Round] increases by one on each aggregation.
+// [Reports] lists the reports submitted to the round so that they can't be
+// replayed, it is cleared with the round.
+type EntityRound struct {
+	Round       uint64
+	EntityType  uint64
+	StartTick   int64
+	Submissions []*RoundSubmission
+	Reports     []ids.ID
+}
+
+func PackEntityRound(er *EntityRound) ([]byte, error) {
+	p := codec.NewWriter(consts.Uint64Len*3+consts.IntLen*2, consts.MaxInt)
+
+	p.PackUint64(er.Round)
+	p.PackUint64(er.EntityType)
+	p.PackInt64(er.StartTick)
+	p.PackInt(len(er.Submissions))
+	for _, s := range er.Submissions {
+		p.PackPublicKey(s.Publisher)
+		p.PackInt64(s.Tick)
+		p.PackBytes(s.Payload)
+	}
+	p.PackInt(len(er.Reports))
+	for _, reportID := range er.Reports {
+		p.PackID(reportID)
+	}
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackEntityRound(v []byte) (*EntityRound, error) {
+	p := codec.NewReader(v, consts.MaxInt)
+
+	er := new(EntityRound)
+	er.Round = p.UnpackUint64(false)
+	er.EntityType = p.UnpackUint64(false)
+	er.StartTick = p.UnpackInt64(false)
+	count := p.UnpackInt(false)
+	er.Submissions = make([]*RoundSubmission, 0, count)
+	for i := 0; i < count && p.Err() == nil; i++ {
+		s := new(RoundSubmission)
+		p.UnpackPublicKey(false, &s.Publisher)
+		s.Tick = p.UnpackInt64(false)
+		p.UnpackBytes(consts.MaxInt, false, &s.Payload)
+		er.Submissions = append(er.Submissions, s)
+	}
+	count = p.UnpackInt(false)
+	for i := 0; i < count && p.Err() == nil; i++ {
+		var reportID ids.ID
+		p.UnpackID(true, &reportID)
+		er.Reports = append(er.Reports, reportID)
+	}
+
+	return er, p.Err()
+}
+
+func StoreEntityRound(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	er *EntityRound,
+) error {
+	k := PrefixEntityRoundKey(entityIndex)
+	v, err := PackEntityRound(er)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+// If round does not exist, an empty round 0 is returned
+func GetEntityRound(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+) (*EntityRound, error) {
+	k := PrefixEntityRoundKey(entityIndex)
+
+	v, err := db.GetValue(ctx, k)
+	if errors.Is(err, database.ErrNotFound) {
+		return &EntityRound{Submissions: make([]*RoundSubmission, 0)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return UnpackEntityRound(v)
+}
+
+// Used to serve RPC queries and restore the oracle
+func GetEntityRoundFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) (*EntityRound, error) {
+	k := PrefixEntityRoundKey(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	if errors.Is(errs[0], database.ErrNotFound) {
+		return &EntityRound{Submissions: make([]*RoundSubmission, 0)}, nil
+	}
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	return UnpackEntityRound(values[0])
+}
+
+// Used to serve RPC queries and restore the oracle
+func GetCachedAggregationResultFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) (bool, *AggregationCache, error) {
+	k := PrefixAggregationCacheResult(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	if errors.Is(errs[0], database.ErrNotFound) {
+		return false, nil, nil
+	}
+	if errs[0] != nil {
+		return false, nil, errs[0]
+	}
+
+	ac, err := UnpackAggregationCache(values[0])
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, ac, nil
+}
+
+// [entityMetaPrefix] + [entityIndex]
+func PrefixEntityMetaKey(entityIndex uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = entityMetaPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+
+	return
+}
+
+func EntityCounterKey() (k []byte) {
+	return []byte{entityCounterPrefix}
+}
+
+func PackEntityMeta(meta *oracle.EntityCollectionMeta) ([]byte, error) {
+	p := codec.NewWriter(consts.Uint64Len*3, consts.MaxInt)
+	meta.Marshal(p)
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackEntityMeta(v []byte) (*oracle.EntityCollectionMeta, error) {
+	return oracle.UnmarshalEntityCollectionMeta(codec.NewReader(v, consts.MaxInt))
+}
+
+func StoreEntityMeta(
+	ctx context.Context,
+	db chain.Database,
+	meta *oracle.EntityCollectionMeta,
+) error {
+	k := PrefixEntityMetaKey(meta.EntityID)
+	v, err := PackEntityMeta(meta)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+func GetEntityMeta(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+) (bool, *oracle.EntityCollectionMeta, error) {
+	k := PrefixEntityMetaKey(entityIndex)
+	return innerGetEntityMeta(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries and restore the oracle
+func GetEntityMetaFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) (bool, *oracle.EntityCollectionMeta, error) {
+	k := PrefixEntityMetaKey(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetEntityMeta(values[0], errs[0])
+}
+
+func innerGetEntityMeta(
+	v []byte,
+	err error,
+) (bool, *oracle.EntityCollectionMeta, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	meta, err := UnpackEntityMeta(v)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, meta, nil
+}
+
+func SetEntityCounter(
+	ctx context.Context,
+	db chain.Database,
+	counter uint64,
+) error {
+	return db.Insert(ctx, EntityCounterKey(), binary.BigEndian.AppendUint64(nil, counter))
+}
+
+func GetEntityCounter(
+	ctx context.Context,
+	db chain.Database,
+) (uint64, error) {
+	return innerGetEntityCounter(db.GetValue(ctx, EntityCounterKey()))
+}
+
+// Used to serve RPC queries and restore the oracle
+func GetEntityCounterFromState(
+	ctx context.Context,
+	f ReadState,
+) (uint64, error) {
+	values, errs := f(ctx, [][]byte{EntityCounterKey()})
+	return innerGetEntityCounter(values[0], errs[0])
+}
+
+func innerGetEntityCounter(
+	v []byte,
+	err error,
+) (uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// StoreEntityRegistration keeps registered entity collections in metadata so
+// that the oracle can be rebuilt without reading state
+func StoreEntityRegistration(
+	_ context.Context,
+	db database.KeyValueWriter,
+	meta *oracle.EntityCollectionMeta,
+) error {
+	k := PrefixEntityMetaKey(meta.EntityID)
+	v, err := PackEntityMeta(meta)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(k, v)
+}
+
+// IterateEntityRegistrations calls [f] with every persisted entity registration
+// in index order
+func IterateEntityRegistrations(
+	db database.Iteratee,
+	f func(meta *oracle.EntityCollectionMeta) error,
+) error {
+	iter := db.NewIteratorWithPrefix([]byte{entityMetaPrefix})
+	defer iter.Release()
+
+	for iter.Next() {
+		meta, err := UnpackEntityMeta(iter.Value())
+		if err != nil {
+			return err
+		}
+		if err := f(meta); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
+
+func AdminKey() (k []byte) {
+	return []byte{adminPrefix}
+}
+
+func SetAdmin(
+	ctx context.Context,
+	db chain.Database,
+	admin crypto.PublicKey,
+) error {
+	return db.Insert(ctx, AdminKey(), admin[:])
+}
+
+func GetAdmin(
+	ctx context.Context,
+	db chain.Database,
+) (bool, crypto.PublicKey, error) {
+	return innerGetAdmin(db.GetValue(ctx, AdminKey()))
+}
+
+func innerGetAdmin(
+	v []byte,
+	err error,
+) (bool, crypto.PublicKey, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, crypto.EmptyPublicKey, nil
+	}
+	if err != nil {
+		return false, crypto.EmptyPublicKey, err
+	}
+	var admin crypto.PublicKey
+	copy(admin[:], v)
+	return true, admin, nil
+}
+
+// [entityFeedersPrefix] + [entityIndex]
+func PrefixEntityFeedersKey(entityIndex uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = entityFeedersPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+
+	return
+}
+
+func PackFeeders(feeders []crypto.PublicKey) ([]byte, error) {
+	p := codec.NewWriter(consts.IntLen+len(feeders)*crypto.PublicKeyLen, consts.MaxInt)
+
+	p.PackInt(len(feeders))
+	for _, feeder := range feeders {
+		p.PackPublicKey(feeder)
+	}
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackFeeders(v []byte) ([]crypto.PublicKey, error) {
+	p := codec.NewReader(v, consts.MaxInt)
+
+	count := p.UnpackInt(false)
+	feeders := make([]crypto.PublicKey, 0, count)
+	for i := 0; i < count && p.Err() == nil; i++ {
+		var feeder crypto.PublicKey
+		p.UnpackPublicKey(true, &feeder)
+		feeders = append(feeders, feeder)
+	}
+
+	return feeders, p.Err()
+}
+
+func StoreEntityFeeders(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	feeders []crypto.PublicKey,
+) error {
+	k := PrefixEntityFeedersKey(entityIndex)
+	v, err := PackFeeders(feeders)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+// GetEntityFeeders returns publishers authorized to upload entities to
+// [entityIndex], no publisher is authorized if the set is never stored
+func GetEntityFeeders(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+) ([]crypto.PublicKey, error) {
+	k := PrefixEntityFeedersKey(entityIndex)
+	return innerGetEntityFeeders(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries
+func GetEntityFeedersFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) ([]crypto.PublicKey, error) {
+	k := PrefixEntityFeedersKey(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetEntityFeeders(values[0], errs[0])
+}
+
+func innerGetEntityFeeders(
+	v []byte,
+	err error,
+) ([]crypto.PublicKey, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return []crypto.PublicKey{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnpackFeeders(v)
+}
+
+// [stakePrefix] + [publisher]
+func PrefixStakeKey(pk crypto.PublicKey) (k []byte) {
+	k = make([]byte, 1+crypto.PublicKeyLen)
+	k[0] = stakePrefix
+	copy(k[1:], pk[:])
 
 	return
 }
+
+// SetStake stores [amount] locked by [pk] and the number of its submissions
+// not aggregated yet, stake can only be withdrawn when [pending] is 0
+func SetStake(
+	ctx context.Context,
+	db chain.Database,
+	pk crypto.PublicKey,
+	amount uint64,
+	pending uint64,
+) error {
+	k := PrefixStakeKey(pk)
+	v := make([]byte, consts.Uint64Len*2)
+	binary.BigEndian.PutUint64(v, amount)
+	binary.BigEndian.PutUint64(v[consts.Uint64Len:], pending)
+
+	return db.Insert(ctx, k, v)
+}
+
+func GetStake(
+	ctx context.Context,
+	db chain.Database,
+	pk crypto.PublicKey,
+) (amount uint64, pending uint64, err error) {
+	k := PrefixStakeKey(pk)
+	return innerGetStake(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries
+func GetStakeFromState(
+	ctx context.Context,
+	f ReadState,
+	pk crypto.PublicKey,
+) (amount uint64, pending uint64, err error) {
+	k := PrefixStakeKey(pk)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetStake(values[0], errs[0])
+}
+
+func innerGetStake(
+	v []byte,
+	err error,
+) (uint64, uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint64(v), binary.BigEndian.Uint64(v[consts.Uint64Len:]), nil
+}
+
+func RewardPoolKey() (k []byte) {
+	return []byte{rewardPoolPrefix}
+}
+
+func SetRewardPool(
+	ctx context.Context,
+	db chain.Database,
+	amount uint64,
+) error {
+	return db.Insert(ctx, RewardPoolKey(), binary.BigEndian.AppendUint64(nil, amount))
+}
+
+func GetRewardPool(
+	ctx context.Context,
+	db chain.Database,
+) (uint64, error) {
+	return innerGetRewardPool(db.GetValue(ctx, RewardPoolKey()))
+}
+
+// Used to serve RPC queries
+func GetRewardPoolFromState(
+	ctx context.Context,
+	f ReadState,
+) (uint64, error) {
+	values, errs := f(ctx, [][]byte{RewardPoolKey()})
+	return innerGetRewardPool(values[0], errs[0])
+}
+
+func innerGetRewardPool(
+	v []byte,
+	err error,
+) (uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// [rewardPrefix] + [publisher]
+func PrefixRewardKey(pk crypto.PublicKey) (k []byte) {
+	k = make([]byte, 1+crypto.PublicKeyLen)
+	k[0] = rewardPrefix
+	copy(k[1:], pk[:])
+
+	return
+}
+
+// AddReward records [amount] earned by [pk] in one aggregation round
+func AddReward(
+	ctx context.Context,
+	db chain.Database,
+	pk crypto.PublicKey,
+	amount uint64,
+) error {
+	k := PrefixRewardKey(pk)
+	earned, rounds, err := innerGetReward(db.GetValue(ctx, k))
+	if err != nil {
+		return err
+	}
+	earned, err = smath.Add64(earned, amount)
+	if err != nil {
+		return err
+	}
+
+	v := make([]byte, consts.Uint64Len*2)
+	binary.BigEndian.PutUint64(v, earned)
+	binary.BigEndian.PutUint64(v[consts.Uint64Len:], rounds+1)
+	return db.Insert(ctx, k, v)
+}
+
+// Used to serve RPC queries
+func GetRewardFromState(
+	ctx context.Context,
+	f ReadState,
+	pk crypto.PublicKey,
+) (earned uint64, rounds uint64, err error) {
+	k := PrefixRewardKey(pk)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetReward(values[0], errs[0])
+}
+
+func innerGetReward(
+	v []byte,
+	err error,
+) (uint64, uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint64(v), binary.BigEndian.Uint64(v[consts.Uint64Len:]), nil
+}
+
+// [observationsPrefix] + [entityIndex]
+func PrefixObservationsKey(entityIndex uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = observationsPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+
+	return
+}
+
+func PackObservations(observations []oracle.Observation) ([]byte, error) {
+	p := codec.NewWriter(consts.IntLen+len(observations)*(consts.Int64Len+consts.Uint64Len), consts.MaxInt)
+
+	p.PackInt(len(observations))
+	for _, o := range observations {
+		p.PackInt64(o.Tick)
+		p.PackUint64(o.Value)
+	}
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackObservations(v []byte) ([]oracle.Observation, error) {
+	p := codec.NewReader(v, consts.MaxInt)
+
+	count := p.UnpackInt(false)
+	observations := make([]oracle.Observation, 0, count)
+	for i := 0; i < count && p.Err() == nil; i++ {
+		observations = append(observations, oracle.Observation{
+			Tick:  p.UnpackInt64(false),
+			Value: p.UnpackUint64(false),
+		})
+	}
+
+	return observations, p.Err()
+}
+
+// AddObservation records [value] of the aggregation result produced at [tick],
+// only the latest [ObservationsMaxLen] observations are kept
+func AddObservation(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	tick int64,
+	value uint64,
+) error {
+	observations, err := GetObservations(ctx, db, entityIndex)
+	if err != nil {
+		return err
+	}
+	observations = append(observations, oracle.Observation{Tick: tick, Value: value})
+	if len(observations) > oconsts.ObservationsMaxLen {
+		observations = observations[len(observations)-oconsts.ObservationsMaxLen:]
+	}
+
+	k := PrefixObservationsKey(entityIndex)
+	v, err := PackObservations(observations)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+// GetObservations returns recent aggregation results of [entityIndex] from the
+// oldest to the latest
+func GetObservations(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+) ([]oracle.Observation, error) {
+	k := PrefixObservationsKey(entityIndex)
+	return innerGetObservations(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries
+func GetObservationsFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) ([]oracle.Observation, error) {
+	k := PrefixObservationsKey(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetObservations(values[0], errs[0])
+}
+
+func innerGetObservations(
+	v []byte,
+	err error,
+) ([]oracle.Observation, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return []oracle.Observation{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnpackObservations(v)
+}
+
+// [roundSlotPrefix] + [entityIndex] + [publisher]
+func PrefixRoundSlotKey(entityIndex uint64, pk crypto.PublicKey) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len+crypto.PublicKeyLen)
+	k[0] = roundSlotPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+	copy(k[1+consts.Uint64Len:], pk[:])
+
+	return
+}
+
+// SetRoundSlot records that [pk] submitted the entity at [position] of
+// aggregation [round] of [entityIndex]
+func SetRoundSlot(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	pk crypto.PublicKey,
+	round uint64,
+	position int,
+) error {
+	k := PrefixRoundSlotKey(entityIndex, pk)
+	v := make([]byte, consts.Uint64Len*2)
+	binary.BigEndian.PutUint64(v, round)
+	binary.BigEndian.PutUint64(v[consts.Uint64Len:], uint64(position))
+
+	return db.Insert(ctx, k, v)
+}
+
+// GetRoundSlot returns the round and position of the latest submission of
+// [pk] to [entityIndex], slots of earlier rounds are outdated
+func GetRoundSlot(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	pk crypto.PublicKey,
+) (exists bool, round uint64, position int, err error) {
+	k := PrefixRoundSlotKey(entityIndex, pk)
+	v, err := db.GetValue(ctx, k)
+	if errors.Is(err, database.ErrNotFound) {
+		return false, 0, 0, nil
+	}
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	return true, binary.BigEndian.Uint64(v), int(binary.BigEndian.Uint64(v[consts.Uint64Len:])), nil
+}
+
+// [aggregationHistoryPrefix] + [entityIndex] + [round]
+func PrefixAggregationHistoryKey(entityIndex uint64, round uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len*2)
+	k[0] = aggregationHistoryPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+	binary.BigEndian.PutUint64(k[1+consts.Uint64Len:], round)
+
+	return
+}
+
+// PackAggregationHistory packs the block timestamp [closed] a round closed at
+// followed by the result valid once it closed, [ac] is nil if the collection
+// had no result yet
+func PackAggregationHistory(closed int64, ac *AggregationCache) ([]byte, error) {
+	var v []byte
+	if ac != nil {
+		var err error
+		v, err = PackAggregationCache(ac)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := codec.NewWriter(consts.Int64Len+consts.BoolLen+len(v), consts.MaxInt)
+	p.PackInt64(closed)
+	p.PackBool(ac != nil)
+	p.PackFixedBytes(v)
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackAggregationHistory(v []byte) (int64, *AggregationCache, error) {
+	p := codec.NewReader(v, consts.MaxInt)
+	closed := p.UnpackInt64(false)
+	recorded := p.UnpackBool()
+	if err := p.Err(); err != nil {
+		return 0, nil, err
+	}
+	if !recorded {
+		return closed, nil, nil
+	}
+
+	ac, err := UnpackAggregationCache(v[consts.Int64Len+consts.BoolLen:])
+	return closed, ac, err
+}
+
+// StoreAggregationHistory records [ac] as the result of [entityIndex] valid
+// once [round] closed at block timestamp [closed], rounds closed without
+// quorum record the retained result marked stale
+func StoreAggregationHistory(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	round uint64,
+	closed int64,
+	ac *AggregationCache,
+) error {
+	k := PrefixAggregationHistoryKey(entityIndex, round)
+	v, err := PackAggregationHistory(closed, ac)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+// GetAggregationHistory returns the block timestamp [round] of [entityIndex]
+// closed at and the result recorded when it closed, false if the round is
+// not closed
+func GetAggregationHistory(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	round uint64,
+) (bool, int64, *AggregationCache, error) {
+	k := PrefixAggregationHistoryKey(entityIndex, round)
+	return innerGetAggregationHistory(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries
+func GetAggregationHistoryFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+	round uint64,
+) (bool, int64, *AggregationCache, error) {
+	k := PrefixAggregationHistoryKey(entityIndex, round)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetAggregationHistory(values[0], errs[0])
+}
+
+func innerGetAggregationHistory(
+	v []byte,
+	err error,
+) (bool, int64, *AggregationCache, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return false, 0, nil, nil
+	}
+	if err != nil {
+		return false, 0, nil, err
+	}
+
+	closed, ac, err := UnpackAggregationHistory(v)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	return true, closed, ac, nil
+}
+
+// [queryBalancePrefix] + [chainID]
+func PrefixQueryBalanceKey(chainID ids.ID) (k []byte) {
+	k = make([]byte, 1+consts.IDLen)
+	k[0] = queryBalancePrefix
+	copy(k[1:], chainID[:])
+
+	return
+}
+
+func SetQueryBalance(
+	ctx context.Context,
+	db chain.Database,
+	chainID ids.ID,
+	amount uint64,
+) error {
+	return db.Insert(ctx, PrefixQueryBalanceKey(chainID), binary.BigEndian.AppendUint64(nil, amount))
+}
+
+// GetQueryBalance returns query fees prepaid for [chainID]
+func GetQueryBalance(
+	ctx context.Context,
+	db chain.Database,
+	chainID ids.ID,
+) (uint64, error) {
+	return innerGetQueryBalance(db.GetValue(ctx, PrefixQueryBalanceKey(chainID)))
+}
+
+// Used to serve RPC queries
+func GetQueryBalanceFromState(
+	ctx context.Context,
+	f ReadState,
+	chainID ids.ID,
+) (uint64, error) {
+	values, errs := f(ctx, [][]byte{PrefixQueryBalanceKey(chainID)})
+	return innerGetQueryBalance(values[0], errs[0])
+}
+
+func innerGetQueryBalance(
+	v []byte,
+	err error,
+) (uint64, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// [subscriptionsPrefix] + [entityIndex]
+func PrefixSubscriptionsKey(entityIndex uint64) (k []byte) {
+	k = make([]byte, 1+consts.Uint64Len)
+	k[0] = subscriptionsPrefix
+	binary.BigEndian.PutUint64(k[1:], entityIndex)
+
+	return
+}
+
+// Subscription registers [DestinationChainID] for pushes of aggregation
+// results crossing its threshold
+type Subscription struct {
+	DestinationChainID ids.ID
+	oracle.PushThreshold
+	// timestamp of the request of the destination chain the subscription
+	// was last updated by
+	Tick int64
+	// value and block timestamp of the last pushed result, 0 if never pushed
+	LastValue uint64
+	LastTick  int64
+}
+
+const subscriptionLen = consts.IDLen + consts.Uint64Len*2 + consts.Int64Len*3
+
+func PackSubscriptions(subs []*Subscription) ([]byte, error) {
+	size := consts.IntLen + len(subs)*subscriptionLen
+	p := codec.NewWriter(size, size)
+
+	p.PackInt(len(subs))
+	for _, sub := range subs {
+		p.PackID(sub.DestinationChainID)
+		p.PackUint64(sub.Deviation)
+		p.PackInt64(sub.Heartbeat)
+		p.PackInt64(sub.Tick)
+		p.PackUint64(sub.LastValue)
+		p.PackInt64(sub.LastTick)
+	}
+
+	return p.Bytes(), p.Err()
+}
+
+func UnpackSubscriptions(v []byte) ([]*Subscription, error) {
+	p := codec.NewReader(v, len(v))
+
+	count := p.UnpackInt(false)
+	subs := make([]*Subscription, 0, count)
+	for i := 0; i < count && p.Err() == nil; i++ {
+		sub := new(Subscription)
+		p.UnpackID(true, &sub.DestinationChainID)
+		sub.Deviation = p.UnpackUint64(false)
+		sub.Heartbeat = p.UnpackInt64(false)
+		sub.Tick = p.UnpackInt64(false)
+		sub.LastValue = p.UnpackUint64(false)
+		sub.LastTick = p.UnpackInt64(false)
+		subs = append(subs, sub)
+	}
+
+	return subs, p.Err()
+}
+
+func SetSubscriptions(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+	subs []*Subscription,
+) error {
+	k := PrefixSubscriptionsKey(entityIndex)
+	v, err := PackSubscriptions(subs)
+	if err != nil {
+		return err
+	}
+
+	return db.Insert(ctx, k, v)
+}
+
+// GetSubscriptions returns chains subscribed to pushes of [entityIndex]
+func GetSubscriptions(
+	ctx context.Context,
+	db chain.Database,
+	entityIndex uint64,
+) ([]*Subscription, error) {
+	k := PrefixSubscriptionsKey(entityIndex)
+	return innerGetSubscriptions(db.GetValue(ctx, k))
+}
+
+// Used to serve RPC queries
+func GetSubscriptionsFromState(
+	ctx context.Context,
+	f ReadState,
+	entityIndex uint64,
+) ([]*Subscription, error) {
+	k := PrefixSubscriptionsKey(entityIndex)
+	values, errs := f(ctx, [][]byte{k})
+	return innerGetSubscriptions(values[0], errs[0])
+}
+
+func innerGetSubscriptions(
+	v []byte,
+	err error,
+) ([]*Subscription, error) {
+	if errors.Is(err, database.ErrNotFound) {
+		return []*Subscription{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnpackSubscriptions(v)
+}
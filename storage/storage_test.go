@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/hypersdk/crypto"
 	"github.com/bianyuanop/oraclevm/oracle"
 	"github.com/bianyuanop/oraclevm/storage"
@@ -17,12 +18,226 @@ func TestPackEnitty(t *testing.T) {
 	publisher := crypto.EmptyPublicKey
 
 	stock := oracle.NewStock("Apple", 10000, publisher, tick)
-	payload := stock.Marshal()
+	payload := marshal(t, stock)
 
 	packed := storage.PackEntity(uint64(entityIndex), uint64(entityType), tick, publisher, payload)
 
 	uI, uTtype, uTick, uPub, uPayload := storage.UnpackEntity(packed)
-	if uI != uint64(entityIndex) || uTtype != uint64(entityType) || tick != uTick || publisher != uPub || reflect.DeepEqual(payload, uPayload) {
+	if uI != uint64(entityIndex) || uTtype != uint64(entityType) || tick != uTick || publisher != uPub || !reflect.DeepEqual(payload, uPayload) {
 		t.Errorf("packed entity is not equal to unpacked Entity")
 	}
 }
+
+func TestPackEntityRound(t *testing.T) {
+	tick := time.Now().Unix()
+	publisher := crypto.EmptyPublicKey
+
+	round := &storage.EntityRound{
+		Round:      3,
+		EntityType: uint64(oracle.StockID),
+		StartTick:  tick,
+		Submissions: []*storage.RoundSubmission{
+			{Publisher: publisher, Tick: tick, Payload: marshal(t, oracle.NewStock("Apple", 10000, publisher, tick))},
+			{Publisher: publisher, Tick: tick + 1, Payload: marshal(t, oracle.NewStock("Apple", 20000, publisher, tick+1))},
+		},
+		Reports: []ids.ID{ids.GenerateTestID()},
+	}
+
+	packed, err := storage.PackEntityRound(round)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackEntityRound(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(round, restored) {
+		t.Errorf("packed round is not equal to unpacked round: %+v, %+v", round, restored)
+	}
+}
+
+func TestPackEntityMeta(t *testing.T) {
+	meta := &oracle.EntityCollectionMeta{
+		EntityName: "Apple",
+		EntityID:   2,
+		EntityType: oracle.StockID,
+		Aggregator: oracle.MeanAggregatorID,
+		Params:     []byte{0x1, 0x2},
+	}
+
+	packed, err := storage.PackEntityMeta(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackEntityMeta(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(meta, restored) {
+		t.Errorf("packed meta is not equal to unpacked meta: %+v, %+v", meta, restored)
+	}
+}
+
+func TestPackFeeders(t *testing.T) {
+	feeders := []crypto.PublicKey{}
+	for i := 0; i < 3; i++ {
+		priv, err := crypto.GeneratePrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		feeders = append(feeders, priv.PublicKey())
+	}
+
+	packed, err := storage.PackFeeders(feeders)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackFeeders(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(feeders, restored) {
+		t.Fatalf("feeders mismatch: %v != %v", feeders, restored)
+	}
+}
+
+func TestPackObservations(t *testing.T) {
+	tick := time.Now().UnixMilli()
+	observations := []oracle.Observation{
+		{Tick: tick, Value: 1000},
+		{Tick: tick + 20, Value: 1010},
+	}
+
+	packed, err := storage.PackObservations(observations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackObservations(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(observations, restored) {
+		t.Fatalf("observations mismatch: %v != %v", observations, restored)
+	}
+}
+
+func TestPackAggregationCache(t *testing.T) {
+	tick := time.Now().UnixMilli()
+	cache := &storage.AggregationCache{
+		EntityType:   uint64(oracle.StockID),
+		Tick:         tick,
+		Round:        3,
+		Contributors: 2,
+		Rejected:     1,
+		Stale:        true,
+		Payload:      marshal(t, oracle.NewStock("Apple", 10000, crypto.EmptyPublicKey, tick)),
+	}
+
+	packed, err := storage.PackAggregationCache(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackAggregationCache(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cache, restored) {
+		t.Fatalf("aggregation cache mismatch: %+v != %+v", cache, restored)
+	}
+}
+
+func TestPackAggregationHistory(t *testing.T) {
+	tick := time.Now().UnixMilli()
+	cache := &storage.AggregationCache{
+		EntityType:   uint64(oracle.StockID),
+		Tick:         tick,
+		Round:        3,
+		Contributors: 2,
+		Rejected:     1,
+		Stale:        true,
+		Payload:      marshal(t, oracle.NewStock("Apple", 10000, crypto.EmptyPublicKey, tick)),
+	}
+
+	packed, err := storage.PackAggregationHistory(tick+1000, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed, restored, err := storage.UnpackAggregationHistory(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if closed != tick+1000 {
+		t.Fatalf("closed tick mismatch: %d != %d", closed, tick+1000)
+	}
+	if !reflect.DeepEqual(cache, restored) {
+		t.Fatalf("aggregation history mismatch: %+v != %+v", cache, restored)
+	}
+
+	// rounds closed before any result record no result
+	packed, err = storage.PackAggregationHistory(tick, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed, restored, err = storage.UnpackAggregationHistory(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if closed != tick || restored != nil {
+		t.Fatalf("empty aggregation history mismatch: %d %+v", closed, restored)
+	}
+}
+
+func TestPackSubscriptions(t *testing.T) {
+	subs := []*storage.Subscription{
+		{
+			DestinationChainID: ids.GenerateTestID(),
+			PushThreshold:      oracle.PushThreshold{Deviation: 100},
+			Tick:               time.Now().UnixMilli(),
+		},
+		{
+			DestinationChainID: ids.GenerateTestID(),
+			PushThreshold:      oracle.PushThreshold{Heartbeat: 60_000},
+			Tick:               time.Now().UnixMilli(),
+			LastValue:          10000,
+			LastTick:           time.Now().UnixMilli(),
+		},
+	}
+
+	packed, err := storage.PackSubscriptions(subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := storage.UnpackSubscriptions(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(subs, restored) {
+		t.Fatalf("subscriptions mismatch: %+v != %+v", subs, restored)
+	}
+}
+
+// marshal returns the encoding of [e], failing [t] on errors
+func marshal(t *testing.T, e oracle.Entity) []byte {
+	t.Helper()
+	payload, err := e.Marshal()
+	if err != nil {
+		t.Fatalf("encoding failed: %+v", err)
+	}
+	return payload
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	smblock "github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
@@ -123,6 +124,11 @@ var (
 
 	networkID uint32
 	gen       *genesis.Genesis
+
+	// chain allowed to query by warp messages
+	queryChainID = ids.GenerateTestID()
+	// key of the only validator of the query chain, see [signWarp]
+	querySk *bls.SecretKey
 )
 
 type instance struct {
@@ -183,12 +189,23 @@ var _ = ginkgo.BeforeSuite(func() {
 		gen.MinUnitPrice = uint64(minPrice)
 	}
 	gen.MinBlockGap = 0
+	gen.MinFeederStake = 10_000
+	gen.SlashingBand = 1_000  // 10%
+	gen.SlashingRatio = 5_000 // 50%
+	gen.RewardPool = 1_000_000
+	gen.RoundReward = 1_000
 	gen.CustomAllocation = []*genesis.CustomAllocation{
 		{
 			Address: sender,
 			Balance: 10_000_000,
 		},
 	}
+	gen.Admin = sender
+	gen.QueryChains = []ids.ID{queryChainID}
+	gen.Entities = []*genesis.EntityRegistration{
+		{Name: "AMD", Type: oracle.StockID, Aggregator: oracle.MeanAggregatorID, Feeders: []string{sender}},
+		{Name: "Apple", Type: oracle.StockID, Aggregator: oracle.MeanAggregatorID, Feeders: []string{sender}},
+	}
 	genesisBytes, err = json.Marshal(gen)
 	gomega.Ω(err).Should(gomega.BeNil())
 
@@ -196,6 +213,22 @@ var _ = ginkgo.BeforeSuite(func() {
 	subnetID := ids.GenerateTestID()
 	chainID := ids.GenerateTestID()
 
+	// warp messages of the query chain are verified against its only validator
+	querySk, err = bls.NewSecretKey()
+	gomega.Ω(err).Should(gomega.BeNil())
+	querySubnetID := ids.GenerateTestID()
+	queryNodeID := ids.GenerateTestNodeID()
+	vdrState := &validators.TestState{
+		GetSubnetIDF: func(context.Context, ids.ID) (ids.ID, error) {
+			return querySubnetID, nil
+		},
+		GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				queryNodeID: {NodeID: queryNodeID, PublicKey: bls.PublicFromSecretKey(querySk), Weight: 1},
+			}, nil
+		},
+	}
+
 	app := &appSender{}
 	for i := range instances {
 		nodeID := ids.GenerateTestNodeID()
@@ -215,7 +248,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			Metrics:        metrics.NewOptionalGatherer(),
 			PublicKey:      bls.PublicFromSecretKey(sk),
 			WarpSigner:     warp.NewSigner(sk, networkID, chainID),
-			ValidatorState: &validators.TestState{},
+			ValidatorState: vdrState,
 		}
 
 		toEngine := make(chan common.Message, 1)
@@ -229,7 +262,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			genesisBytes,
 			nil,
 			[]byte(
-				`{"parallelism":3, "testMode":true, "logLevel":"debug", "trackedStocks": ["AMD", "Apple"]}`,
+				`{"parallelism":3, "testMode":true, "logLevel":"debug"}`,
 			),
 			toEngine,
 			nil,
@@ -736,6 +769,460 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		gomega.Ω(metas[1].EntityType).Should(gomega.Equal(apple.EntityType))
 	})
 
+	ginkgo.It("register entity collection", func() {
+		ginkgo.By("reject registering from non-admin", func() {
+			results := sendActionFrom(instances[0], &actions.RegisterEntity{
+				EntityIndex: 2,
+				EntityName:  "Intel",
+				EntityType:  oracle.StockID,
+				Aggregator:  oracle.MeanAggregatorID,
+			}, factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputNotAdmin))
+		})
+
+		ginkgo.By("reject registering an out of order index", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 3,
+				EntityName:  "Intel",
+				EntityType:  oracle.StockID,
+				Aggregator:  oracle.MeanAggregatorID,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputEntityIndexMismatch))
+		})
+
+		ginkgo.By("register the next index", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 2,
+				EntityName:  "Intel",
+				EntityType:  oracle.StockID,
+				Aggregator:  oracle.MeanAggregatorID,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			time.Sleep(2 * time.Second)
+
+			metas, err := instances[0].lcli.AvailableEntities(context.TODO())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(metas).Should(gomega.HaveLen(3))
+			gomega.Ω(metas[2].EntityID).Should(gomega.Equal(uint64(2)))
+			gomega.Ω(metas[2].EntityName).Should(gomega.Equal("Intel"))
+		})
+
+		ginkgo.By("reject uploading to an unregistered index", func() {
+			results := sendAction(instances[0], &actions.UploadEntity{
+				EntityIndex: 3,
+				EntityType:  oracle.StockID,
+				Payload:     encode(oracle.NewStock("NVDA", 1999, crypto.EmptyPublicKey, 0)),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputEntityNotRegistered))
+		})
+	})
+
+	ginkgo.It("authorize entity feeders", func() {
+		// vary payloads so that resubmissions are not duplicate transactions
+		upload := func(price uint64) *actions.UploadEntity {
+			return &actions.UploadEntity{
+				EntityIndex: 2,
+				EntityType:  oracle.StockID,
+				Payload:     encode(oracle.NewStock("Intel", price, crypto.EmptyPublicKey, 0)),
+			}
+		}
+
+		ginkgo.By("reject uploads from unauthorized publishers", func() {
+			results := sendActionFrom(instances[0], upload(1999), factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputUnauthorizedPublisher))
+		})
+
+		ginkgo.By("reject feeder updates from non-admin", func() {
+			results := sendActionFrom(instances[0], &actions.UpdateFeeder{
+				EntityIndex: 2,
+				Feeder:      rsender2,
+				Authorized:  true,
+			}, factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputNotAdmin))
+		})
+
+		ginkgo.By("authorize a feeder", func() {
+			results := sendAction(instances[0], &actions.UpdateFeeder{
+				EntityIndex: 2,
+				Feeder:      rsender2,
+				Authorized:  true,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			feeders, err := instances[0].lcli.Feeders(context.TODO(), 2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(feeders).Should(gomega.Equal([]string{sender2}))
+
+			results = sendActionFrom(instances[0], upload(2999), factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("revoke a feeder", func() {
+			results := sendAction(instances[0], &actions.UpdateFeeder{
+				EntityIndex: 2,
+				Feeder:      rsender2,
+				Authorized:  false,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			feeders, err := instances[0].lcli.Feeders(context.TODO(), 2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(feeders).Should(gomega.BeEmpty())
+
+			results = sendActionFrom(instances[0], upload(3999), factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputUnauthorizedPublisher))
+		})
+	})
+
+	ginkgo.It("stake and slash deviating publishers", func() {
+		upload := func(price uint64) *actions.UploadEntity {
+			return &actions.UploadEntity{
+				EntityIndex: 2,
+				EntityType:  oracle.StockID,
+				Payload:     encode(oracle.NewStock("Intel", price, crypto.EmptyPublicKey, 0)),
+			}
+		}
+
+		ginkgo.By("settle the pending round", func() {
+			results := aggregate(instances[0], 2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			_, pending, err := instances[0].lcli.Stake(context.TODO(), sender2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pending).Should(gomega.Equal(uint64(0)))
+
+			earned, rounds, err := instances[0].lcli.Rewards(context.TODO(), sender2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(earned).Should(gomega.Equal(uint64(1_000)))
+			gomega.Ω(rounds).Should(gomega.Equal(uint64(1)))
+
+			pool, err := instances[0].lcli.RewardPool(context.TODO())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pool).Should(gomega.Equal(uint64(999_000)))
+		})
+
+		ginkgo.By("allow uploads from staked publishers", func() {
+			results := sendAction(instances[0], &actions.Transfer{
+				To:    rsender3,
+				Value: 100_000,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			results = sendActionFrom(instances[0], upload(1399), factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputUnauthorizedPublisher))
+
+			results = sendActionFrom(instances[0], &actions.Stake{Amount: 10_000}, factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			results = sendActionFrom(instances[0], upload(1400), factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			stake, pending, err := instances[0].lcli.Stake(context.TODO(), sender3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stake).Should(gomega.Equal(uint64(10_000)))
+			gomega.Ω(pending).Should(gomega.Equal(uint64(1)))
+		})
+
+		ginkgo.By("lock stake until submissions are aggregated", func() {
+			results := sendActionFrom(instances[0], &actions.Unstake{Amount: 10_000}, factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputStakeLocked))
+		})
+
+		ginkgo.By("replace submissions of the same publisher in a round", func() {
+			results := sendActionFrom(instances[0], upload(1200), factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			round, err := instances[0].lcli.Round(context.TODO(), 2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(round.Submissions).Should(gomega.Equal(1))
+
+			_, pending, err := instances[0].lcli.Stake(context.TODO(), sender3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pending).Should(gomega.Equal(uint64(1)))
+		})
+
+		ginkgo.By("slash publishers deviating from the aggregate", func() {
+			results := sendAction(instances[0], &actions.UpdateFeeder{
+				EntityIndex: 2,
+				Feeder:      rsender,
+				Authorized:  true,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			results = sendActionFrom(instances[0], &actions.Stake{Amount: 10_000}, factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			// 1000 is replaced by 1003, mean is 1068, only 1200 deviates more than 10%
+			for _, price := range []uint64{1000, 1003} {
+				results = sendAction(instances[0], upload(price))
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+			results = sendActionFrom(instances[0], upload(1001), factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			time.Sleep(10 * time.Millisecond)
+			round, err := instances[0].lcli.Round(context.TODO(), 2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			results = sendAction(instances[0], &actions.Aggregate{
+				EntityIndex: 2,
+				Round:       round.Round + 1,
+				Publishers:  []crypto.PublicKey{rsender, rsender2},
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputRoundMismatch))
+
+			results = sendAction(instances[0], &actions.Aggregate{
+				EntityIndex: 2,
+				Round:       round.Round,
+				Publishers:  []crypto.PublicKey{rsender},
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputMissingPublisher))
+
+			results = aggregate(instances[0], 2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			stake, pending, err := instances[0].lcli.Stake(context.TODO(), sender3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stake).Should(gomega.Equal(uint64(5_000)))
+			gomega.Ω(pending).Should(gomega.Equal(uint64(0)))
+
+			_, pending, err = instances[0].lcli.Stake(context.TODO(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pending).Should(gomega.Equal(uint64(0)))
+
+			// only accepted publishers share the reward
+			earned, rounds, err := instances[0].lcli.Rewards(context.TODO(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(earned).Should(gomega.Equal(uint64(500)))
+			gomega.Ω(rounds).Should(gomega.Equal(uint64(1)))
+
+			earned, rounds, err = instances[0].lcli.Rewards(context.TODO(), sender2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(earned).Should(gomega.Equal(uint64(1_500)))
+			gomega.Ω(rounds).Should(gomega.Equal(uint64(2)))
+
+			earned, _, err = instances[0].lcli.Rewards(context.TODO(), sender3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(earned).Should(gomega.Equal(uint64(0)))
+
+			// slashed stake is added to the pool
+			pool, err := instances[0].lcli.RewardPool(context.TODO())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pool).Should(gomega.Equal(uint64(1_003_000)))
+		})
+
+		ginkgo.By("withdraw the remaining stake", func() {
+			results := sendActionFrom(instances[0], &actions.Unstake{Amount: 5_000}, factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			stake, _, err := instances[0].lcli.Stake(context.TODO(), sender3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stake).Should(gomega.Equal(uint64(0)))
+		})
+	})
+
+	ginkgo.It("reject outliers from aggregation", func() {
+		ginkgo.By("register a collection with an outlier filter", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 3,
+				EntityName:  "NVDA",
+				EntityType:  oracle.StockID,
+				Aggregator:  oracle.MeanAggregatorID,
+				Params:      []byte(`{"maxDeviation":2000}`),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			for _, feeder := range []crypto.PublicKey{rsender, rsender2, rsender3} {
+				results = sendAction(instances[0], &actions.UpdateFeeder{
+					EntityIndex: 3,
+					Feeder:      feeder,
+					Authorized:  true,
+				})
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+		})
+
+		ginkgo.By("report rejected entities in the aggregation output", func() {
+			prices := []uint64{1000, 1010, 5000}
+			for i, f := range []chain.AuthFactory{factory, factory2, factory3} {
+				results := sendActionFrom(instances[0], &actions.UploadEntity{
+					EntityIndex: 3,
+					EntityType:  oracle.StockID,
+					Payload:     encode(oracle.NewStock("NVDA", prices[i], crypto.EmptyPublicKey, 0)),
+				}, f)
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+
+			results := aggregate(instances[0], 3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Entity.(*oracle.Stock).Price).Should(gomega.Equal(uint64(1005)))
+			gomega.Ω(entityWithMeta.Rejected).Should(gomega.HaveLen(1))
+			gomega.Ω(entityWithMeta.Rejected[0].Publisher).Should(gomega.Equal(sender3))
+			gomega.Ω(entityWithMeta.Rejected[0].Entity.(*oracle.Stock).Price).Should(gomega.Equal(uint64(5000)))
+		})
+
+		ginkgo.By("close the round as stale when every submission is rejected", func() {
+			// median is 1650, both deviate 3333 basis points
+			for i, f := range []chain.AuthFactory{factory, factory2} {
+				results := sendActionFrom(instances[0], &actions.UploadEntity{
+					EntityIndex: 3,
+					EntityType:  oracle.StockID,
+					Payload:     encode(oracle.NewStock("NVDA", uint64(i+1)*1100, crypto.EmptyPublicKey, 0)),
+				}, f)
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+
+			results := aggregate(instances[0], 3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Stale).Should(gomega.BeTrue())
+			gomega.Ω(entityWithMeta.Entity.(*oracle.Stock).Price).Should(gomega.Equal(uint64(1005)))
+			gomega.Ω(entityWithMeta.Rejected).Should(gomega.HaveLen(2))
+
+			round, err := instances[0].lcli.Round(context.Background(), 3)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(round.Submissions).Should(gomega.Equal(0))
+			_, pending, err := instances[0].lcli.Stake(context.TODO(), sender2)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(pending).Should(gomega.Equal(uint64(0)))
+		})
+	})
+
+	ginkgo.It("require a quorum of publishers", func() {
+		ginkgo.By("register a collection requiring two publishers", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 4,
+				EntityName:  "Intel",
+				EntityType:  oracle.StockID,
+				Aggregator:  oracle.MeanAggregatorID,
+				Params:      []byte(`{"minPublishers":2}`),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			for _, feeder := range []crypto.PublicKey{rsender, rsender2} {
+				results = sendAction(instances[0], &actions.UpdateFeeder{
+					EntityIndex: 4,
+					Feeder:      feeder,
+					Authorized:  true,
+				})
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+		})
+
+		upload := func(f chain.AuthFactory, price uint64) {
+			results := sendActionFrom(instances[0], &actions.UploadEntity{
+				EntityIndex: 4,
+				EntityType:  oracle.StockID,
+				Payload:     encode(oracle.NewStock("Intel", price, crypto.EmptyPublicKey, 0)),
+			}, f)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+		}
+
+		ginkgo.By("close the round as stale below quorum", func() {
+			upload(factory, 30)
+			upload(factory, 31)
+
+			results := aggregate(instances[0], 4)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Stale).Should(gomega.BeTrue())
+			gomega.Ω(entityWithMeta.Entity).Should(gomega.BeNil())
+
+			round, err := instances[0].lcli.Round(context.Background(), 4)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(round.Round).Should(gomega.Equal(uint64(1)))
+			gomega.Ω(round.Submissions).Should(gomega.Equal(0))
+
+			history, length, err := instances[0].lcli.AggregationHistory(context.Background(), 4, 10)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(length).Should(gomega.Equal(0))
+			gomega.Ω(history).Should(gomega.BeEmpty())
+		})
+
+		ginkgo.By("publish once distinct publishers reach quorum", func() {
+			upload(factory, 32)
+			upload(factory2, 34)
+
+			results := aggregate(instances[0], 4)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Stale).Should(gomega.BeFalse())
+			gomega.Ω(entityWithMeta.Entity.(*oracle.Stock).Price).Should(gomega.Equal(uint64(33)))
+		})
+
+		ginkgo.By("retain the previous result when quorum is missed", func() {
+			upload(factory2, 35)
+
+			results := aggregate(instances[0], 4)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Stale).Should(gomega.BeTrue())
+			gomega.Ω(entityWithMeta.Entity.(*oracle.Stock).Price).Should(gomega.Equal(uint64(33)))
+
+			_, length, err := instances[0].lcli.AggregationHistory(context.Background(), 4, 10)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(length).Should(gomega.Equal(1))
+		})
+	})
+
 	ginkgo.It("testing functionality of entity execution", func() {
 		parser, err := instances[0].lcli.Parser(context.Background())
 		gomega.Ω(err).Should(gomega.BeNil())
@@ -746,7 +1233,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			&actions.UploadEntity{
 				EntityIndex: 0,
 				EntityType:  0,
-				Payload:     []byte(`{ "ticker": "AMD", "price": 1999 }`),
+				Payload:     encode(oracle.NewStock("AMD", 1999, crypto.EmptyPublicKey, 0)),
 			},
 			factory,
 		)
@@ -765,6 +1252,14 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		gomega.Ω(s.Price).Should(gomega.Equal(uint64(1999)))
 		gomega.Ω(s.Ticker).Should(gomega.Equal("AMD"))
 
+		results = aggregate(instances[0], 0)
+		gomega.Ω(results).Should(gomega.HaveLen(1))
+		gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+		entityWithMeta, err = oracle.UnmarshalEntityWithMeta(results[0].Output)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(entityWithMeta.ID).Should(gomega.Equal(uint64(0)))
+		gomega.Ω(entityWithMeta.Type).Should(gomega.Equal(uint64(oracle.StockID)))
+
 		time.Sleep(2 * time.Second)
 
 		count, err := instances[0].lcli.CollectionCount(context.TODO(), 0)
@@ -789,6 +1284,15 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		})
 
 		ginkgo.By("submitting two transactions", func() {
+			// each publisher holds one submission per round
+			results := sendAction(instances[0], &actions.UpdateFeeder{
+				EntityIndex: 0,
+				Feeder:      rsender2,
+				Authorized:  true,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
 			parser, err := instances[0].lcli.Parser(context.Background())
 			gomega.Ω(err).Should(gomega.BeNil())
 			submit, _, _, err := instances[0].cli.GenerateTransaction(
@@ -798,7 +1302,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 				&actions.UploadEntity{
 					EntityIndex: 0,
 					EntityType:  0,
-					Payload:     []byte(`{ "ticker": "AMD", "price": 20 }`),
+					Payload:     encode(oracle.NewStock("AMD", 20, crypto.EmptyPublicKey, 0)),
 				},
 				factory,
 			)
@@ -812,9 +1316,9 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 				&actions.UploadEntity{
 					EntityIndex: 0,
 					EntityType:  0,
-					Payload:     []byte(`{ "ticker": "AMD", "price": 30 }`),
+					Payload:     encode(oracle.NewStock("AMD", 30, crypto.EmptyPublicKey, 0)),
 				},
-				factory,
+				factory2,
 			)
 			gomega.Ω(err2).Should(gomega.BeNil())
 			gomega.Ω(submit2(context.Background())).Should(gomega.BeNil())
@@ -824,6 +1328,10 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			accept := expectBlk(instances[0])
 			results := accept()
 			gomega.Ω(results).Should(gomega.HaveLen(2))
+
+			results = aggregate(instances[0], 0)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
 			time.Sleep(2 * time.Second)
 
 			historyLen, err := instances[0].lcli.CollectionCount(context.TODO(), 0)
@@ -852,7 +1360,7 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 				&actions.UploadEntity{
 					EntityIndex: 0,
 					EntityType:  0,
-					Payload:     []byte(`{ "ticker": "AMD", "price": 999 }`),
+					Payload:     encode(oracle.NewStock("AMD", 999, crypto.EmptyPublicKey, 0)),
 				},
 				factory,
 			)
@@ -861,6 +1369,152 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			accept := expectBlk(instances[0])
 			results := accept()
 			gomega.Ω(results).Should(gomega.HaveLen(1))
+
+			results = aggregate(instances[0], 0)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("serve time-weighted average of aggregation results", func() {
+			// the latest result holds over windows ending after it
+			tick := instances[0].vm.LastAcceptedBlock().Tmstmp + 1
+			reply, err := instances[0].lcli.Twap(context.Background(), 0, 1, tick)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(reply.EntityType).Should(gomega.Equal(uint64(0)))
+			stock, err := oracle.UnmarshalStock(reply.Payload)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stock.Ticker).Should(gomega.Equal("AMD"))
+			gomega.Ω(stock.Price).Should(gomega.Equal(uint64(999)))
+
+			_, err = instances[0].lcli.Twap(context.Background(), 0, 0, tick)
+			gomega.Ω(err).ShouldNot(gomega.BeNil())
+
+			wq := &actions.WarpQuery{
+				EntityIndex:        0,
+				DestinationChainID: ids.GenerateTestID(),
+				Mode:               actions.TWAPQueryMode,
+				Window:             60 * consts.MillisecondsPerSecond,
+				MaxAge:             10 * consts.MillisecondsPerSecond,
+			}
+			wtb, err := wq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			restored, err := actions.UnmarshalWarpQuery(wtb)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(restored).Should(gomega.Equal(wq))
+		})
+
+		ginkgo.By("resolve the round closed at a tick", func() {
+			round, err := instances[0].lcli.Round(context.Background(), 0)
+			gomega.Ω(err).Should(gomega.BeNil())
+			tick := instances[0].vm.LastAcceptedBlock().Tmstmp
+			closed, err := instances[0].lcli.RoundAt(context.Background(), 0, tick)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(closed).Should(gomega.Equal(round.Round - 1))
+
+			// no round closed before the chain started
+			_, err = instances[0].lcli.RoundAt(context.Background(), 0, 1)
+			gomega.Ω(err).ShouldNot(gomega.BeNil())
+		})
+
+		ginkgo.By("encode point-in-time queries", func() {
+			for _, wq := range []*actions.WarpQuery{
+				{
+					EntityIndex:        0,
+					DestinationChainID: ids.GenerateTestID(),
+					Mode:               actions.AtTickQueryMode,
+					Tick:               time.Now().UnixMilli(),
+				},
+				{
+					EntityIndex:        0,
+					DestinationChainID: ids.GenerateTestID(),
+					Mode:               actions.AtRoundQueryMode,
+					Round:              1,
+				},
+			} {
+				wtb, err := wq.Marshal()
+				gomega.Ω(err).Should(gomega.BeNil())
+				restored, err := actions.UnmarshalWarpQuery(wtb)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(restored).Should(gomega.Equal(wq))
+			}
+
+			// timestamps must be set for time queries
+			wq := &actions.WarpQuery{
+				EntityIndex:        0,
+				DestinationChainID: ids.GenerateTestID(),
+				Mode:               actions.AtTickQueryMode,
+			}
+			wtb, err := wq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			_, err = actions.UnmarshalWarpQuery(wtb)
+			gomega.Ω(err).Should(gomega.Equal(actions.ErrInvalidTick))
+		})
+
+		ginkgo.By("encode batch queries", func() {
+			bwq := &actions.BatchWarpQuery{
+				EntityIndices:      []uint64{0, 1, 2},
+				DestinationChainID: ids.GenerateTestID(),
+				Mode:               actions.TWAPQueryMode,
+				Window:             60 * consts.MillisecondsPerSecond,
+			}
+			wtb, err := bwq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			restored, err := actions.UnmarshalBatchWarpQuery(wtb)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(restored).Should(gomega.Equal(bwq))
+
+			queries := restored.Queries()
+			gomega.Ω(queries).Should(gomega.HaveLen(3))
+			gomega.Ω(queries[2].EntityIndex).Should(gomega.Equal(uint64(2)))
+			gomega.Ω(queries[2].Window).Should(gomega.Equal(bwq.Window))
+
+			// entities can't be queried twice in one batch
+			bwq.EntityIndices = []uint64{0, 0}
+			wtb, err = bwq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			_, err = actions.UnmarshalBatchWarpQuery(wtb)
+			gomega.Ω(err).Should(gomega.Equal(actions.ErrDuplicateEntityIndex))
+
+			bwq.EntityIndices = []uint64{}
+			wtb, err = bwq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			_, err = actions.UnmarshalBatchWarpQuery(wtb)
+			gomega.Ω(err).Should(gomega.Equal(actions.ErrInvalidBatchSize))
+
+			// time queries carry the round closed at the tick of every entity
+			bwq.EntityIndices = []uint64{0, 1}
+			bwq.Mode = actions.AtTickQueryMode
+			bwq.Tick = time.Now().UnixMilli()
+			wtb, err = bwq.Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			uwm, err := warp.NewUnsignedMessage(networkID, ids.Empty, wtb)
+			gomega.Ω(err).Should(gomega.BeNil())
+			wm, err := warp.NewMessage(uwm, &warp.BitSetSignature{})
+			gomega.Ω(err).Should(gomega.BeNil())
+			for _, rounds := range [][]uint64{{1}, {1, 2}} {
+				p := codec.NewWriter(0, consts.MaxInt)
+				(&actions.BatchQuery{Rounds: rounds}).Marshal(p)
+				gomega.Ω(p.Err()).Should(gomega.BeNil())
+				action, err := actions.UnmarshalBatchQuery(codec.NewReader(p.Bytes(), consts.MaxInt), wm)
+				if len(rounds) != len(bwq.EntityIndices) {
+					gomega.Ω(err).Should(gomega.Equal(actions.ErrRoundsMismatch))
+					continue
+				}
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(action.(*actions.BatchQuery).Rounds).Should(gomega.Equal(rounds))
+			}
+		})
+
+		ginkgo.By("only accept queries of allowed chains", func() {
+			r := gen.Rules(time.Now().UnixMilli(), networkID, instances[0].chainID)
+			allowed, _, _ := r.GetWarpConfig(queryChainID)
+			gomega.Ω(allowed).Should(gomega.BeTrue())
+			allowed, _, _ = r.GetWarpConfig(ids.GenerateTestID())
+			gomega.Ω(allowed).Should(gomega.BeFalse())
+
+			chains, ok := r.FetchCustom(lconsts.QueryChainsKey)
+			gomega.Ω(ok).Should(gomega.BeTrue())
+			gomega.Ω(chains).Should(gomega.Equal([]ids.ID{queryChainID}))
 		})
 
 		ginkgo.By("submit query transaction", func() {
@@ -897,6 +1551,40 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 			)
 			fmt.Fprintf(ginkgo.GinkgoWriter, "transactionid: %s\n", txID.String())
 			gomega.Ω(err).Should(gomega.BeNil())
+
+			// warp messages of chains not allowed to query are not verified
+			results := expectWarpBlk(instances[0])()
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputWarpVerificationFailed))
+		})
+
+		ginkgo.By("prepay query fees of the querying chain", func() {
+			balance, err := instances[0].lcli.Balance(context.Background(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+
+			results := sendAction(instances[0], &actions.FundQueries{
+				ChainID: queryChainID,
+				Amount:  5_000,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			prepaid, err := instances[0].lcli.QueryBalance(context.Background(), queryChainID)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(prepaid).Should(gomega.Equal(uint64(5_000)))
+
+			// fees are paid from the balance of the funding account
+			after, err := instances[0].lcli.Balance(context.Background(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(after).Should(gomega.BeNumerically("<=", balance-5_000))
+
+			results = sendAction(instances[0], &actions.FundQueries{
+				ChainID: queryChainID,
+				Amount:  1_000_000_000,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
 		})
 
 		// ginkgo.By("build block & check query result", func() {
@@ -917,9 +1605,512 @@ var _ = ginkgo.Describe("[Tx Processing]", func() {
 		// 	gomega.Ω(stk.Ticker).Should(gomega.Equal("AMD"))
 		// })
 	})
+
+	ginkgo.It("test subscriptions", func() {
+		// pushResult uploads [price] to AMD, aggregates it and returns the
+		// pushed result, nil if nothing is pushed
+		pushResult := func(price uint64) *actions.PushResult {
+			results := sendAction(instances[0], &actions.UploadEntity{
+				EntityIndex: 0,
+				EntityType:  0,
+				Payload:     encode(oracle.NewStock("AMD", price, crypto.EmptyPublicKey, 0)),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			// identical aggregations within a second are duplicate transactions
+			time.Sleep(time.Second)
+			results = aggregate(instances[0], 0)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			if results[0].WarpMessage == nil {
+				return nil
+			}
+			var push actions.PushResult
+			gomega.Ω(json.Unmarshal(results[0].WarpMessage.Payload, &push)).Should(gomega.BeNil())
+			return &push
+		}
+
+		// request returns the warp message of [sourceChainID] requesting a
+		// subscription to AMD
+		request := func(sourceChainID ids.ID, deviation uint64, tick int64) *warp.Message {
+			payload, err := (&actions.WarpSubscription{
+				EntityIndex: 0,
+				Deviation:   deviation,
+				Tick:        tick,
+			}).Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			return signWarp(sourceChainID, payload)
+		}
+		subscribe := func(wm *warp.Message, f chain.AuthFactory) *chain.Result {
+			results := sendWarpAction(instances[0], wm, &actions.Subscribe{}, f)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			return results[0]
+		}
+		var (
+			subscription *warp.Message
+			tick         int64
+		)
+
+		ginkgo.By("subscribe chains requesting it", func() {
+			result := subscribe(request(ids.GenerateTestID(), 100, time.Now().UnixMilli()), factory)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(actions.OutputWarpVerificationFailed))
+
+			// requests must be signed by the subscribing chain
+			unsigned, err := warp.NewMessage(&request(queryChainID, 100, time.Now().UnixMilli()).UnsignedMessage, &warp.BitSetSignature{})
+			gomega.Ω(err).Should(gomega.BeNil())
+			result = subscribe(unsigned, factory)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(actions.OutputWarpVerificationFailed))
+
+			result = subscribe(request(queryChainID, 100, time.Now().UnixMilli()-2*gen.ValidityWindow), factory)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(actions.OutputSubscriptionExpired))
+
+			tick = time.Now().UnixMilli()
+			subscription = request(queryChainID, 100 /* 1% */, tick)
+			gomega.Ω(subscribe(subscription, factory).Success).Should(gomega.BeTrue())
+
+			subs, err := instances[0].lcli.Subscriptions(context.Background(), 0)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(subs).Should(gomega.HaveLen(1))
+			gomega.Ω(subs[0].DestinationChainID).Should(gomega.Equal(queryChainID))
+			gomega.Ω(subs[0].Tick).Should(gomega.Equal(tick))
+			gomega.Ω(subs[0].LastTick).Should(gomega.Equal(int64(0)))
+		})
+
+		ginkgo.By("push results crossing the deviation", func() {
+			// the first result is always pushed
+			push := pushResult(2000)
+			gomega.Ω(push).ShouldNot(gomega.BeNil())
+			gomega.Ω(push.DestinationChainIDs).Should(gomega.Equal([]ids.ID{queryChainID}))
+			gomega.Ω(push.Result.EntityIndex).Should(gomega.Equal(uint64(0)))
+			stock, err := oracle.UnmarshalStock(push.Result.Payload)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stock.Price).Should(gomega.Equal(uint64(2000)))
+
+			gomega.Ω(pushResult(2010)).Should(gomega.BeNil())
+
+			push = pushResult(2030)
+			gomega.Ω(push).ShouldNot(gomega.BeNil())
+			stock, err = oracle.UnmarshalStock(push.Result.Payload)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stock.Price).Should(gomega.Equal(uint64(2030)))
+
+			subs, err := instances[0].lcli.Subscriptions(context.Background(), 0)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(subs[0].LastValue).Should(gomega.Equal(uint64(2030)))
+		})
+
+		ginkgo.By("cancel subscriptions by newer requests only", func() {
+			// relayers can't replay requests of the chain
+			result := subscribe(subscription, factory2)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(actions.OutputWarpVerificationFailed))
+
+			// nor relay them out of order
+			result = subscribe(request(queryChainID, 0, tick-1), factory2)
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(actions.OutputSubscriptionOutdated))
+
+			gomega.Ω(subscribe(request(queryChainID, 0, time.Now().UnixMilli()), factory).Success).Should(gomega.BeTrue())
+
+			subs, err := instances[0].lcli.Subscriptions(context.Background(), 0)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(subs).Should(gomega.BeEmpty())
+		})
+
+		ginkgo.By("push stale results on heartbeat", func() {
+			// Intel retains its last result when quorum is missed
+			payload, err := (&actions.WarpSubscription{
+				EntityIndex: 4,
+				Heartbeat:   1,
+				Tick:        time.Now().UnixMilli(),
+			}).Marshal()
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(subscribe(signWarp(queryChainID, payload), factory).Success).Should(gomega.BeTrue())
+
+			results := sendAction(instances[0], &actions.UploadEntity{
+				EntityIndex: 4,
+				EntityType:  oracle.StockID,
+				Payload:     encode(oracle.NewStock("Intel", 36, crypto.EmptyPublicKey, 0)),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			results = aggregate(instances[0], 4)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			gomega.Ω(results[0].WarpMessage).ShouldNot(gomega.BeNil())
+
+			var push actions.PushResult
+			gomega.Ω(json.Unmarshal(results[0].WarpMessage.Payload, &push)).Should(gomega.BeNil())
+			gomega.Ω(push.DestinationChainIDs).Should(gomega.Equal([]ids.ID{queryChainID}))
+			gomega.Ω(push.Result.EntityIndex).Should(gomega.Equal(uint64(4)))
+			gomega.Ω(push.Result.Stale).Should(gomega.BeTrue())
+			stock, err := oracle.UnmarshalStock(push.Result.Payload)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(stock.Price).Should(gomega.Equal(uint64(33)))
+
+			// stale results carry no value to compare later results against
+			subs, err := instances[0].lcli.Subscriptions(context.Background(), 4)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(subs[0].LastValue).Should(gomega.Equal(uint64(0)))
+			gomega.Ω(subs[0].LastTick).ShouldNot(gomega.Equal(int64(0)))
+		})
+	})
+	ginkgo.It("settle sport events by consensus", func() {
+		ginkgo.By("register a sport collection", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 5,
+				EntityName:  "final-1",
+				EntityType:  oracle.SportID,
+				Aggregator:  oracle.MeanAggregatorID,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+
+			results = sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 5,
+				EntityName:  "final-1",
+				EntityType:  oracle.SportID,
+				Aggregator:  oracle.ConsensusAggregatorID,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			for _, feeder := range []crypto.PublicKey{rsender, rsender2, rsender3} {
+				results = sendAction(instances[0], &actions.UpdateFeeder{
+					EntityIndex: 5,
+					Feeder:      feeder,
+					Authorized:  true,
+				})
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+		})
+
+		upload := func(f chain.AuthFactory, home uint64, away uint64) {
+			results := sendActionFrom(instances[0], &actions.UploadEntity{
+				EntityIndex: 5,
+				EntityType:  oracle.SportID,
+				Payload: encode(oracle.NewSport(
+					"final-1", "Lions", "Tigers", home, away, oracle.SportFinished, true, crypto.EmptyPublicKey, 0,
+				)),
+			}, f)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+		}
+
+		ginkgo.By("close the round as stale on a split vote", func() {
+			upload(factory, 2, 1)
+			upload(factory2, 1, 1)
+
+			results := aggregate(instances[0], 5)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Stale).Should(gomega.BeTrue())
+			gomega.Ω(entityWithMeta.Entity).Should(gomega.BeNil())
+
+			round, err := instances[0].lcli.Round(context.Background(), 5)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(round.Submissions).Should(gomega.Equal(0))
+		})
+
+		ginkgo.By("settle the outcome reported by the majority", func() {
+			upload(factory2, 2, 1)
+			upload(factory3, 2, 1)
+
+			results := aggregate(instances[0], 5)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(entityWithMeta.Type).Should(gomega.Equal(uint64(oracle.SportID)))
+			sport, ok := entityWithMeta.Entity.(*oracle.Sport)
+			gomega.Ω(ok).Should(gomega.BeTrue())
+			gomega.Ω(sport.EventID).Should(gomega.Equal("final-1"))
+			gomega.Ω(sport.HomeScore).Should(gomega.Equal(uint64(2)))
+			gomega.Ω(sport.AwayScore).Should(gomega.Equal(uint64(1)))
+			gomega.Ω(sport.Final).Should(gomega.BeTrue())
+		})
+	})
+	ginkgo.It("aggregate numeric feeds of different decimals", func() {
+		ginkgo.By("register a numeric feed collection", func() {
+			results := sendAction(instances[0], &actions.RegisterEntity{
+				EntityIndex: 6,
+				EntityName:  "EUR/USD",
+				EntityType:  oracle.NumericFeedID,
+				Aggregator:  oracle.MeanAggregatorID,
+				Params:      []byte(`{"decimals":4,"unit":"USD"}`),
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			for _, feeder := range []crypto.PublicKey{rsender, rsender2} {
+				results = sendAction(instances[0], &actions.UpdateFeeder{
+					EntityIndex: 6,
+					Feeder:      feeder,
+					Authorized:  true,
+				})
+				gomega.Ω(results).Should(gomega.HaveLen(1))
+				gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+			}
+		})
+
+		upload := func(f chain.AuthFactory, feed *oracle.NumericFeed) *chain.Result {
+			results := sendActionFrom(instances[0], &actions.UploadEntity{
+				EntityIndex: 6,
+				EntityType:  oracle.NumericFeedID,
+				Payload:     encode(feed),
+			}, f)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			return results[0]
+		}
+
+		ginkgo.By("reject uploads of other units", func() {
+			result := upload(factory, oracle.NewNumericFeed(108, 2, "EUR", 0, crypto.EmptyPublicKey, 0))
+			gomega.Ω(result.Success).Should(gomega.BeFalse())
+			gomega.Ω(result.Output).Should(gomega.Equal(oracle.NewFieldError("unit", oracle.ErrUnitMismatch).Marshal()))
+		})
+
+		ginkgo.By("scale submissions to the collection decimals", func() {
+			gomega.Ω(upload(factory, oracle.NewNumericFeed(108, 2, "USD", 0, crypto.EmptyPublicKey, 0)).Success).Should(gomega.BeTrue())
+			gomega.Ω(upload(factory2, oracle.NewNumericFeed(110000, 5, "USD", 200, crypto.EmptyPublicKey, 0)).Success).Should(gomega.BeTrue())
+
+			results := aggregate(instances[0], 6)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			feed, ok := entityWithMeta.Entity.(*oracle.NumericFeed)
+			gomega.Ω(ok).Should(gomega.BeTrue())
+			gomega.Ω(feed.Value).Should(gomega.Equal(uint64(10_900)))
+			gomega.Ω(feed.Decimals).Should(gomega.Equal(uint8(4)))
+			gomega.Ω(feed.Unit).Should(gomega.Equal("USD"))
+			gomega.Ω(feed.Confidence).Should(gomega.Equal(uint64(10)))
+			gomega.Ω(oracle.Render(feed)).Should(gomega.Equal("1.0900 ± 0.0010 USD"))
+		})
+	})
+	ginkgo.It("validate uploaded entities", func() {
+		valid := encode(oracle.NewStock("AMD", 1, crypto.EmptyPublicKey, 0))
+		for _, tc := range []struct {
+			payload []byte
+			output  []byte
+		}{
+			{valid[:len(valid)-1], actions.OutputMalformedPayload},
+			// JSON payloads are only decoded from records persisted by previous versions
+			{[]byte(`{ "ticker": "AMD", "price": 1 }`), actions.OutputMalformedPayload},
+			{encode(oracle.NewStock("", 0, crypto.EmptyPublicKey, 0)), oracle.NewFieldError("ticker", oracle.ErrRequiredField).Marshal()},
+			{encode(oracle.NewStock("AMD", 0, crypto.EmptyPublicKey, 0)), oracle.NewFieldError("price", oracle.ErrNotPositive).Marshal()},
+			{encode(oracle.NewStock("Apple", 1000, crypto.EmptyPublicKey, 0)), oracle.NewFieldError("ticker", oracle.ErrNameMismatch).Marshal()},
+		} {
+			results := sendAction(instances[0], &actions.UploadEntity{
+				EntityIndex: 0,
+				EntityType:  oracle.StockID,
+				Payload:     tc.payload,
+			})
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(tc.output))
+		}
+	})
+	ginkgo.It("submit reports signed by many feeders", func() {
+		var report *actions.SubmitReport
+		ginkgo.By("reject reports with invalid signatures", func() {
+			sportRound, err := instances[0].lcli.Round(context.Background(), 5)
+			gomega.Ω(err).Should(gomega.BeNil())
+			feedRound, err := instances[0].lcli.Round(context.Background(), 6)
+			gomega.Ω(err).Should(gomega.BeNil())
+
+			sport := encode(oracle.NewSport("final-1", "Lions", "Tigers", 0, 3, oracle.SportFinished, true, crypto.EmptyPublicKey, 0))
+			report = &actions.SubmitReport{
+				Tick: time.Now().UnixMilli(),
+				Signatures: []*actions.ReportSignature{
+					{Signer: rsender},
+					{Signer: rsender2},
+				},
+				Observations: []*actions.ReportObservation{
+					{Signer: 0, EntityType: oracle.NumericFeedID, EntityIndex: 6, Round: feedRound.Round, Payload: encode(oracle.NewNumericFeed(120, 2, "USD", 0, crypto.EmptyPublicKey, 0))},
+					{Signer: 1, EntityType: oracle.NumericFeedID, EntityIndex: 6, Round: feedRound.Round, Payload: encode(oracle.NewNumericFeed(1300, 3, "USD", 0, crypto.EmptyPublicKey, 0))},
+					{Signer: 0, EntityType: oracle.SportID, EntityIndex: 5, Round: sportRound.Round, Payload: sport},
+					{Signer: 1, EntityType: oracle.SportID, EntityIndex: 5, Round: sportRound.Round, Payload: sport},
+				},
+			}
+			gomega.Ω(report.Sign(instances[0].chainID, priv)).Should(gomega.BeNil())
+			gomega.Ω(report.Sign(instances[0].chainID, priv2)).Should(gomega.BeNil())
+			gomega.Ω(report.Sign(instances[0].chainID, priv3)).Should(gomega.Equal(actions.ErrUnknownSigner))
+
+			// observations changed by the transmitter invalidate signatures
+			tampered := *report
+			tampered.Observations = append([]*actions.ReportObservation{{
+				Signer:      0,
+				EntityType:  oracle.NumericFeedID,
+				EntityIndex: 6,
+				Round:       feedRound.Round,
+				Payload:     encode(oracle.NewNumericFeed(200, 2, "USD", 0, crypto.EmptyPublicKey, 0)),
+			}}, report.Observations[1:]...)
+			results := sendActionFrom(instances[0], &tampered, factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputInvalidReportSignature))
+		})
+
+		ginkgo.By("submit observations of every signer", func() {
+			results := sendActionFrom(instances[0], report, factory3)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			// replays within the round would overwrite later uploads of signers
+			results = sendActionFrom(instances[0], report, factory2)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(results[0].Output).Should(gomega.Equal(actions.OutputReportSubmitted))
+
+			round, err := instances[0].lcli.Round(context.Background(), 6)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(round.Submissions).Should(gomega.Equal(2))
+
+			// identical aggregations within a second are duplicate transactions
+			time.Sleep(time.Second)
+			results = aggregate(instances[0], 5)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeTrue())
+
+			entityWithMeta, err := oracle.UnmarshalEntityWithMeta(results[0].Output)
+			gomega.Ω(err).Should(gomega.BeNil())
+			sport, ok := entityWithMeta.Entity.(*oracle.Sport)
+			gomega.Ω(ok).Should(gomega.BeTrue())
+			gomega.Ω(sport.EventID).Should(gomega.Equal("final-1"))
+			gomega.Ω(sport.AwayScore).Should(gomega.Equal(uint64(3)))
+		})
+
+		ginkgo.By("reject reports observing earlier rounds", func() {
+			stale := &actions.SubmitReport{
+				Tick: report.Tick + 1,
+				Signatures: []*actions.ReportSignature{
+					{Signer: rsender},
+					{Signer: rsender2},
+				},
+				Observations: report.Observations,
+			}
+			gomega.Ω(stale.Sign(instances[0].chainID, priv)).Should(gomega.BeNil())
+			gomega.Ω(stale.Sign(instances[0].chainID, priv2)).Should(gomega.BeNil())
+
+			results := sendActionFrom(instances[0], stale, factory)
+			gomega.Ω(results).Should(gomega.HaveLen(1))
+			gomega.Ω(results[0].Success).Should(gomega.BeFalse())
+			gomega.Ω(string(results[0].Output)).Should(gomega.Equal("observation 2: " + string(actions.OutputReportRoundMismatch)))
+		})
+	})
 })
 
+// aggregate submits an [actions.Aggregate] for [entityIndex] and accepts the
+// block containing it
+func aggregate(i instance, entityIndex uint64) []*chain.Result {
+	// rounds can only be aggregated in a block later than their start, blocks
+	// built in the same millisecond share a timestamp
+	time.Sleep(10 * time.Millisecond)
+
+	round, err := i.lcli.Round(context.Background(), entityIndex)
+	gomega.Ω(err).Should(gomega.BeNil())
+	publishers := make([]crypto.PublicKey, len(round.Publishers))
+	for j, addr := range round.Publishers {
+		publishers[j], err = utils.ParseAddress(addr)
+		gomega.Ω(err).Should(gomega.BeNil())
+	}
+
+	return sendAction(i, &actions.Aggregate{
+		EntityIndex: entityIndex,
+		Round:       round.Round,
+		Publishers:  publishers,
+	})
+}
+
+// signWarp returns the warp message carrying [payload] sent by
+// [sourceChainID], signed by the validator of the query chain
+func signWarp(sourceChainID ids.ID, payload []byte) *warp.Message {
+	uwm, err := warp.NewUnsignedMessage(networkID, sourceChainID, payload)
+	gomega.Ω(err).Should(gomega.BeNil())
+	sig, err := warp.NewSigner(querySk, networkID, sourceChainID).Sign(uwm)
+	gomega.Ω(err).Should(gomega.BeNil())
+
+	signers := set.NewBits(0)
+	signature := &warp.BitSetSignature{Signers: signers.Bytes()}
+	copy(signature.Signature[:], sig)
+	wm, err := warp.NewMessage(uwm, signature)
+	gomega.Ω(err).Should(gomega.BeNil())
+	return wm
+}
+
+// sendWarpAction submits [action] carrying [wm] signed by [f] and accepts
+// the block containing it
+func sendWarpAction(i instance, wm *warp.Message, action chain.Action, f chain.AuthFactory) []*chain.Result {
+	parser, err := i.lcli.Parser(context.Background())
+	gomega.Ω(err).Should(gomega.BeNil())
+	submit, _, _, err := i.cli.GenerateTransaction(
+		context.Background(),
+		parser,
+		wm,
+		action,
+		f,
+	)
+	gomega.Ω(err).Should(gomega.BeNil())
+	gomega.Ω(submit(context.Background())).Should(gomega.BeNil())
+
+	accept := expectWarpBlk(i)
+	return accept()
+}
+
+// encode returns the binary encoding of [e] uploaded by publishers
+func encode(e oracle.Entity) []byte {
+	payload, err := e.Marshal()
+	gomega.Ω(err).Should(gomega.BeNil())
+	return payload
+}
+
+// sendAction submits [action] signed by [factory] and accepts the block
+// containing it
+func sendAction(i instance, action chain.Action) []*chain.Result {
+	return sendActionFrom(i, action, factory)
+}
+
+// sendActionFrom submits [action] signed by [f] and accepts the block
+// containing it
+func sendActionFrom(i instance, action chain.Action, f chain.AuthFactory) []*chain.Result {
+	parser, err := i.lcli.Parser(context.Background())
+	gomega.Ω(err).Should(gomega.BeNil())
+	submit, _, _, err := i.cli.GenerateTransaction(
+		context.Background(),
+		parser,
+		nil,
+		action,
+		f,
+	)
+	gomega.Ω(err).Should(gomega.BeNil())
+	gomega.Ω(submit(context.Background())).Should(gomega.BeNil())
+
+	accept := expectBlk(i)
+	return accept()
+}
+
+// expectWarpBlk is [expectBlk] building and verifying the block with a block
+// context, which is required to verify warp messages
+func expectWarpBlk(i instance) func() []*chain.Result {
+	return expectBlkWithContext(i, &smblock.Context{PChainHeight: 1})
+}
+
 func expectBlk(i instance) func() []*chain.Result {
+	return expectBlkWithContext(i, nil)
+}
+
+func expectBlkWithContext(i instance, bctx *smblock.Context) func() []*chain.Result {
 	ctx := context.TODO()
 
 	// manually signal ready
@@ -927,14 +2118,26 @@ func expectBlk(i instance) func() []*chain.Result {
 	// manually ack ready sig as in engine
 	<-i.toEngine
 
-	blk, err := i.vm.BuildBlock(ctx)
+	var (
+		blk snowman.Block
+		err error
+	)
+	if bctx == nil {
+		blk, err = i.vm.BuildBlock(ctx)
+	} else {
+		blk, err = i.vm.BuildBlockWithContext(ctx, bctx)
+	}
 	if err != nil {
 		panic(err)
 	}
 	gomega.Ω(err).To(gomega.BeNil())
 	gomega.Ω(blk).To(gomega.Not(gomega.BeNil()))
 
-	gomega.Ω(blk.Verify(ctx)).To(gomega.BeNil())
+	if bctx == nil {
+		gomega.Ω(blk.Verify(ctx)).To(gomega.BeNil())
+	} else {
+		gomega.Ω(blk.(smblock.WithVerifyContext).VerifyWithContext(ctx, bctx)).To(gomega.BeNil())
+	}
 	gomega.Ω(blk.Status()).To(gomega.Equal(choices.Processing))
 
 	err = i.vm.SetPreference(ctx, blk.ID())